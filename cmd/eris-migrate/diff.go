@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change in a diff.
+const contextLines = 3
+
+// edit is a single line of an edit script: ' ' for unchanged lines, '-' for deleted lines, and '+' for inserted
+// lines. aLine and bLine are the number of lines of each text before this line.
+type edit struct {
+	op           byte
+	text         string
+	aLine, bLine int
+}
+
+// diff returns a unified diff of two texts or nil if they're equal.
+func diff(aName, bName string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	edits := editScript(splitLines(a), splitLines(b))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "diff %v %v\n--- %v\n+++ %v\n", aName, bName, aName, bName)
+	for i := 0; i < len(edits); {
+		// skip to the next change
+		for i < len(edits) && edits[i].op == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// extend the hunk until the next change is too far away to share the context
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(edits) && edits[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(edits) && edits[next].op == ' ' {
+				next++
+			}
+			if next < len(edits) && next-end <= 2*contextLines {
+				end = next
+				continue
+			}
+			end += contextLines
+			if end > len(edits) {
+				end = len(edits)
+			}
+			break
+		}
+
+		writeHunk(&buf, edits[start:end])
+		i = end
+	}
+	return buf.Bytes()
+}
+
+// writeHunk writes a single hunk of a unified diff.
+func writeHunk(buf *bytes.Buffer, edits []edit) {
+	var aLen, bLen int
+	for _, e := range edits {
+		if e.op != '+' {
+			aLen++
+		}
+		if e.op != '-' {
+			bLen++
+		}
+	}
+	fmt.Fprintf(buf, "@@ -%v +%v @@\n", hunkRange(edits[0].aLine, aLen), hunkRange(edits[0].bLine, bLen))
+	for _, e := range edits {
+		buf.WriteByte(e.op)
+		buf.WriteString(e.text)
+		if !strings.HasSuffix(e.text, "\n") {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange returns the range of a hunk in the unified diff format.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%v,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%v,%v", start+1, length)
+}
+
+// splitLines splits a text into lines that keep their line endings.
+func splitLines(text []byte) []string {
+	var lines []string
+	for len(text) > 0 {
+		i := bytes.IndexByte(text, '\n') + 1
+		if i == 0 {
+			i = len(text)
+		}
+		lines = append(lines, string(text[:i]))
+		text = text[i:]
+	}
+	return lines
+}
+
+// editScript returns the shortest edit script that turns a into b using the Myers diff algorithm.
+func editScript(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back through the trace to recover the edits
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{op: ' ', text: a[x], aLine: x, bLine: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, edit{op: '+', text: b[y], aLine: x, bLine: y})
+			} else {
+				x--
+				edits = append(edits, edit{op: '-', text: a[x], aLine: x, bLine: y})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,130 @@
+// Command eris-migrate rewrites code that uses github.com/pkg/errors to use eris instead.
+//
+// The calls errors.New, errors.Errorf, errors.Wrap, errors.Wrapf, errors.WithStack, errors.WithMessage,
+// errors.WithMessagef, errors.Cause, errors.Is, errors.As, and errors.Unwrap are replaced with their eris equivalents,
+// and the pkg/errors import is replaced with eris. Comparisons with errors.Cause (e.g. errors.Cause(err) == ErrX or a
+// switch statement on the cause) are replaced with eris.Is because eris.Cause doesn't return global errors that were
+// wrapped. Anything else (e.g. errors.StackTrace or interfaces with a Cause method) is reported with its position, and
+// the pkg/errors import is kept for it.
+//
+// Usage:
+//
+//	eris-migrate [flags] [path ...]
+//
+// Each path is a Go source file or a directory (optionally followed by /...) that's searched recursively (vendor,
+// testdata, and hidden directories are skipped). Without flags, the rewritten source is printed to the standard output.
+//
+// The flags are:
+//
+//	-d  display diffs instead of rewriting files (dry run)
+//	-l  list files whose source would change
+//	-w  write the result to the source files
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	diffMode  = flag.Bool("d", false, "display diffs instead of rewriting files (dry run)")
+	listMode  = flag.Bool("l", false, "list files whose source would change")
+	writeMode = flag.Bool("w", false, "write the result to the source files")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: eris-migrate [flags] [path ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	exitCode := 0
+	for _, path := range flag.Args() {
+		if err := migratePath(path, os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	os.Exit(exitCode)
+}
+
+// migratePath migrates a single file or all Go files in a directory.
+func migratePath(path string, stdout, stderr io.Writer) error {
+	// directories are always searched recursively, so package patterns like ./... are accepted as well
+	if path == "..." || strings.HasSuffix(path, "/...") {
+		path = filepath.Clean(strings.TrimSuffix(path, "..."))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return migrateFile(path, stdout, stderr)
+	}
+	root := path
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		return migrateFile(path, stdout, stderr)
+	})
+}
+
+// migrateFile migrates a single file and prints or writes the result according to the flags. Patterns that have
+// to be migrated by hand are reported on stderr.
+func migrateFile(filename string, stdout, stderr io.Writer) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	res, diags, err := migrate(filename, src)
+	if err != nil {
+		return err
+	}
+	for _, d := range diags {
+		fmt.Fprintln(stderr, d)
+	}
+
+	changed := !bytes.Equal(src, res)
+	if *listMode && changed {
+		fmt.Fprintln(stdout, filename)
+	}
+	if *writeMode && changed {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if *diffMode {
+		_, err = stdout.Write(diff(filename+".orig", filename, src, res))
+		return err
+	}
+	if !*listMode && !*writeMode {
+		_, err = stdout.Write(res)
+	}
+	return err
+}
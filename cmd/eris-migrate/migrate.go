@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+const (
+	pkgErrorsPath = "github.com/pkg/errors"
+	erisPath      = "github.com/rotisserie/eris"
+)
+
+// replacements maps the supported pkg/errors functions to their eris equivalents.
+var replacements = map[string]string{
+	"New":          "New",
+	"Errorf":       "Errorf",
+	"Wrap":         "Wrap",
+	"Wrapf":        "Wrapf",
+	"WithStack":    "WithStack",
+	"WithMessage":  "Wrap",
+	"WithMessagef": "Wrapf",
+	"Cause":        "Cause", // comparisons with the cause are rewritten to Is (see rewriteCauseComparisons)
+	"Is":           "Is",
+	"As":           "As",
+	"Unwrap":       "Unwrap",
+}
+
+// diagnostic is a pattern that can't be migrated automatically.
+type diagnostic struct {
+	pos token.Position
+	msg string
+}
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%v: %v", d.pos, d.msg)
+}
+
+// migrate rewrites the pkg/errors calls of a Go source file to their eris equivalents and fixes the imports. It
+// returns the formatted source and the patterns that have to be migrated by hand. If the file doesn't import
+// pkg/errors, the source is returned unchanged.
+func migrate(filename string, src []byte) ([]byte, []diagnostic, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkgSpec := findImport(file, pkgErrorsPath)
+	if pkgSpec == nil {
+		return src, nil, nil
+	}
+	pkgName := importName(pkgSpec, "errors")
+	erisName := "eris"
+	erisSpec := findImport(file, erisPath)
+	if erisSpec != nil {
+		erisName = importName(erisSpec, "eris")
+	}
+
+	// comparisons with the cause have to be rewritten before the selectors because eris keeps the root errors of the
+	// chain in Wrap (i.e. eris.Cause(err) == ErrX is always false for a wrapped global error)
+	diags := rewriteCauseComparisons(fset, file, pkgName)
+
+	// rewrite selectors of the pkg/errors package and collect the ones that aren't supported
+	var rewritten, kept int // number of rewritten and remaining pkg/errors selectors
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ident, ok := n.X.(*ast.Ident)
+			if !ok || ident.Name != pkgName || ident.Obj != nil {
+				return true
+			}
+			if name, ok := replacements[n.Sel.Name]; ok {
+				ident.Name = erisName
+				n.Sel.Name = name
+				rewritten++
+			} else {
+				kept++
+				diags = append(diags, diagnostic{
+					pos: fset.Position(n.Pos()),
+					msg: fmt.Sprintf("%v.%v has no eris equivalent", pkgName, n.Sel.Name),
+				})
+			}
+		case *ast.InterfaceType:
+			for _, method := range n.Methods.List {
+				for _, name := range method.Names {
+					if name.Name == "Cause" {
+						diags = append(diags, diagnostic{
+							pos: fset.Position(name.Pos()),
+							msg: "eris doesn't call Cause methods, implement Unwrap instead",
+						})
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	// fix the imports: the pkg/errors import is only kept if it's still used (diagnostics of patterns that were
+	// rewritten anyway, e.g. switch statements on the cause, don't keep it)
+	switch {
+	case kept == 0 && erisSpec == nil:
+		pkgSpec.Name = nil
+		pkgSpec.Path.Value = strconv.Quote(erisPath)
+	case kept == 0:
+		deleteImport(file, pkgSpec)
+	case erisSpec == nil && rewritten > 0:
+		addImport(file, pkgSpec, erisPath)
+	}
+	ast.SortImports(fset, file)
+
+	sort.Slice(diags, func(i, j int) bool {
+		return diags[i].pos.Offset < diags[j].pos.Offset
+	})
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), diags, nil
+}
+
+// rewriteCauseComparisons rewrites comparisons of the cause of an error with another error into calls of Is:
+//
+//	errors.Cause(err) == ErrX  ->  errors.Is(err, ErrX)
+//	errors.Cause(err) != ErrX  ->  !errors.Is(err, ErrX)
+//
+// Switch statements on the cause are rewritten into switch statements with an Is call per case if the error is a
+// variable, so it's safe to evaluate it once per case. Other switch statements are returned as diagnostics.
+func rewriteCauseComparisons(fset *token.FileSet, file *ast.File, pkgName string) []diagnostic {
+	var diags []diagnostic
+	replaceExprs(file, func(expr ast.Expr) ast.Expr {
+		b, ok := expr.(*ast.BinaryExpr)
+		if !ok || (b.Op != token.EQL && b.Op != token.NEQ) {
+			return expr
+		}
+		x, other := b.X, b.Y
+		if causeArg(x, pkgName) == nil {
+			x, other = other, x
+		}
+		arg := causeArg(x, pkgName)
+		if arg == nil {
+			return expr
+		}
+		call := isCall(pkgName, b.Pos(), arg, other)
+		if b.Op == token.NEQ {
+			return &ast.UnaryExpr{OpPos: b.Pos(), Op: token.NOT, X: call}
+		}
+		return call
+	})
+	ast.Inspect(file, func(n ast.Node) bool {
+		s, ok := n.(*ast.SwitchStmt)
+		if !ok {
+			return true
+		}
+		arg := causeArg(s.Tag, pkgName)
+		if arg == nil {
+			return true
+		}
+		if _, ok := arg.(*ast.Ident); !ok {
+			diags = append(diags, diagnostic{
+				pos: fset.Position(s.Tag.Pos()),
+				msg: fmt.Sprintf("switch on %v.Cause compares the root error, use eris.Is in the cases instead", pkgName),
+			})
+			return true
+		}
+		s.Tag = nil
+		for _, stmt := range s.Body.List {
+			clause := stmt.(*ast.CaseClause)
+			for i, target := range clause.List {
+				// the calls are placed at the cases, so they're printed in the same place
+				err := &ast.Ident{NamePos: target.Pos(), Name: arg.(*ast.Ident).Name}
+				clause.List[i] = isCall(pkgName, target.Pos(), err, target)
+			}
+		}
+		return true
+	})
+	return diags
+}
+
+// causeArg returns the argument of a Cause call of the pkg/errors package or nil if the expression isn't one.
+func causeArg(expr ast.Expr, pkgName string) ast.Expr {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Cause" {
+		return nil
+	}
+	if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != pkgName || ident.Obj != nil {
+		return nil
+	}
+	return call.Args[0]
+}
+
+// isCall returns a call of the Is function of the pkg/errors package at the given position. The selector is rewritten
+// to eris like the other pkg/errors selectors.
+func isCall(pkgName string, pos token.Pos, err, target ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   &ast.Ident{NamePos: pos, Name: pkgName},
+			Sel: &ast.Ident{NamePos: pos, Name: "Is"},
+		},
+		Lparen: pos,
+		Args:   []ast.Expr{err, target},
+		Rparen: target.End(),
+	}
+}
+
+var (
+	exprType      = reflect.TypeOf((*ast.Expr)(nil)).Elem()
+	exprSliceType = reflect.TypeOf([]ast.Expr(nil))
+)
+
+// replaceExprs replaces the expressions of a syntax tree, which are visited in depth-first order. The replace
+// function returns the given expression if it shouldn't be replaced.
+func replaceExprs(node ast.Node, replace func(ast.Expr) ast.Expr) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		v := reflect.ValueOf(n)
+		if !v.IsValid() || v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+			return true
+		}
+		v = v.Elem()
+		for i := 0; i < v.NumField(); i++ {
+			switch field := v.Field(i); field.Type() {
+			case exprType:
+				replaceField(field, replace)
+			case exprSliceType:
+				for j := 0; j < field.Len(); j++ {
+					replaceField(field.Index(j), replace)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// replaceField replaces the expression of a struct field or slice element.
+func replaceField(field reflect.Value, replace func(ast.Expr) ast.Expr) {
+	expr, ok := field.Interface().(ast.Expr)
+	if !ok || expr == nil {
+		return
+	}
+	if r := replace(expr); r != expr {
+		field.Set(reflect.ValueOf(r))
+	}
+}
+
+// findImport returns the import spec of a package or nil if the file doesn't import it.
+func findImport(file *ast.File, path string) *ast.ImportSpec {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err == nil && p == path {
+			return spec
+		}
+	}
+	return nil
+}
+
+// importName returns the name a package is imported as.
+func importName(spec *ast.ImportSpec, defaultName string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return defaultName
+}
+
+// deleteImport removes an import spec from a file.
+func deleteImport(file *ast.File, spec *ast.ImportSpec) {
+	for i, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for j, s := range gen.Specs {
+			if s != spec {
+				continue
+			}
+			gen.Specs = append(gen.Specs[:j], gen.Specs[j+1:]...)
+			if len(gen.Specs) == 0 {
+				file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+			}
+			break
+		}
+	}
+	for i, s := range file.Imports {
+		if s == spec {
+			file.Imports = append(file.Imports[:i], file.Imports[i+1:]...)
+			break
+		}
+	}
+}
+
+// addImport adds an import spec next to an existing one.
+func addImport(file *ast.File, next *ast.ImportSpec, path string) {
+	spec := &ast.ImportSpec{
+		Path: &ast.BasicLit{ValuePos: next.Pos(), Kind: token.STRING, Value: strconv.Quote(path)},
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, s := range gen.Specs {
+			if s == next {
+				if !gen.Lparen.IsValid() {
+					gen.Lparen = gen.Pos()
+					gen.Rparen = next.End()
+				}
+				gen.Specs = append(gen.Specs, spec)
+				file.Imports = append(file.Imports, spec)
+				return
+			}
+		}
+	}
+}
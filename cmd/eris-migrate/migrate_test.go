@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestMigrate(t *testing.T) {
+	tests := map[string]struct {
+		diags []string // expected diagnostics
+	}{
+		"wrap":      {},
+		"alias":     {},
+		"existing":  {},
+		"unchanged": {},
+		"unsupported": {
+			diags: []string{
+				"testdata/unsupported.input:6:2: eris doesn't call Cause methods, implement Unwrap instead",
+				"testdata/unsupported.input:10:15: errors.StackTrace has no eris equivalent",
+				"testdata/unsupported.input:17:26: errors.Frame has no eris equivalent",
+				"testdata/unsupported.input:25:9: switch on errors.Cause compares the root error, use eris.Is in the cases instead",
+			},
+		},
+		"rewritten": {
+			diags: []string{
+				"testdata/rewritten.input:6:2: eris doesn't call Cause methods, implement Unwrap instead",
+				"testdata/rewritten.input:14:9: switch on errors.Cause compares the root error, use eris.Is in the cases instead",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := filepath.Join("testdata", name+".input")
+			src, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, diags, err := migrate(input, src)
+			if err != nil {
+				t.Fatalf("migrate() failed: %v", err)
+			}
+			gotDiff := diff(input+".orig", input, src, got)
+
+			golden := filepath.Join("testdata", name+".golden")
+			goldenDiff := filepath.Join("testdata", name+".diff")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenDiff, gotDiff, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("migrate() got unexpected output:\n%s", diff(golden, input, want, got))
+			}
+			if want, err := os.ReadFile(goldenDiff); err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(gotDiff, want) {
+				t.Errorf("diff() got\n%s\nwant\n%s", gotDiff, want)
+			}
+
+			var gotDiags []string
+			for _, d := range diags {
+				gotDiags = append(gotDiags, d.String())
+			}
+			if !reflect.DeepEqual(gotDiags, tt.diags) {
+				t.Errorf("migrate() got diagnostics %q want %q", gotDiags, tt.diags)
+			}
+
+			// migrating the result again doesn't change it
+			if again, _, err := migrate(golden, got); err != nil || !bytes.Equal(again, got) {
+				t.Errorf("migrate() isn't idempotent: %v\n%s", err, diff(golden, golden, got, again))
+			}
+		})
+	}
+}
+
+func TestMigrateSyntaxError(t *testing.T) {
+	if _, _, err := migrate("broken.go", []byte("package example\n\nfunc {")); err == nil {
+		t.Errorf("migrate() expected a syntax error")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := map[string]struct {
+		a, b   string
+		output string
+	}{
+		"equal": {
+			a:      "a\nb\n",
+			b:      "a\nb\n",
+			output: "",
+		},
+		"changed line": {
+			a:      "a\nb\nc\n",
+			b:      "a\nx\nc\n",
+			output: "diff a b\n--- a\n+++ b\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		"inserted line": {
+			a:      "a\n",
+			b:      "a\nb\n",
+			output: "diff a b\n--- a\n+++ b\n@@ -1 +1,2 @@\n a\n+b\n",
+		},
+		"deleted line": {
+			a:      "a\nb\n",
+			b:      "b\n",
+			output: "diff a b\n--- a\n+++ b\n@@ -1,2 +1 @@\n-a\n b\n",
+		},
+		"empty text": {
+			a:      "",
+			b:      "a\n",
+			output: "diff a b\n--- a\n+++ b\n@@ -0,0 +1 @@\n+a\n",
+		},
+		"missing newline": {
+			a:      "a",
+			b:      "a\n",
+			output: "diff a b\n--- a\n+++ b\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n",
+		},
+		"separate hunks": {
+			a:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:      "x\n2\n3\n4\n5\n6\n7\n8\n9\ny\n",
+			output: "diff a b\n--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+y\n",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := string(diff("a", "b", []byte(tt.a), []byte(tt.b))); got != tt.output {
+				t.Errorf("diff() got\n%q\nwant\n%q", got, tt.output)
+			}
+		})
+	}
+}
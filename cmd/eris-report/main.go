@@ -0,0 +1,80 @@
+// Command eris-report groups the eris errors in JSON logs and reports how often each of them occurred.
+//
+// It reads newline-delimited JSON logs from the given files (or the standard input). Each line is either an eris JSON
+// document (see eris.ToJSON) or a log entry with an eris document in a top-level field (e.g. "error"). Errors are
+// grouped by their fingerprint (see eris.Fingerprint), i.e. their root error message and the function names of their
+// root stack, and each group is reported with its number of errors, the time the errors were first and last seen, the
+// most common wrap paths, and a representative trace. The time of a log entry is taken from its "time", "ts",
+// "timestamp", or "@timestamp" field. If an entry doesn't have a time, the positions in the logs are reported instead.
+//
+// Usage:
+//
+//   eris-report [flags] [file ...]
+//
+// The flags are:
+//
+//   -json  write the report as JSON
+//   -n     maximum number of groups to report (0 reports all groups)
+//   -top   maximum number of wrap paths per group (default 3)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+var (
+	jsonMode  = flag.Bool("json", false, "write the report as JSON")
+	maxGroups = flag.Int("n", 0, "maximum number of groups to report (0 reports all groups)")
+	topPaths  = flag.Int("top", 3, "maximum number of wrap paths per group")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: eris-report [flags] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	r := newReport()
+	exitCode := 0
+	if flag.NArg() == 0 {
+		if err := r.read("<stdin>", os.Stdin); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	for _, path := range flag.Args() {
+		if err := readFile(r, path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+
+	r.finish(*topPaths)
+	if *maxGroups > 0 && len(r.Groups) > *maxGroups {
+		r.Groups = r.Groups[:*maxGroups]
+	}
+	var err error
+	if *jsonMode {
+		err = r.writeJSON(os.Stdout)
+	} else {
+		err = r.writeText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = 1
+	}
+	os.Exit(exitCode)
+}
+
+// readFile adds the errors in a single log file to the report.
+func readFile(r *report, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.read(path, f)
+}
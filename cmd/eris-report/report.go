@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// jsonFormat is the eris JSON format of the errors in the logs. MergeTrace is set, so the messages next to merged
+// frames are parsed as well, and frames without a message are parsed the same way.
+var jsonFormat = eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, WithExternal: true, MergeTrace: true})
+
+// timeKeys are the keys of log entry fields that are used as the time of an entry, in order of precedence.
+var timeKeys = []string{"time", "ts", "timestamp", "@timestamp"}
+
+// group is a set of errors with the same fingerprint.
+type group struct {
+	Fingerprint string          `json:"fingerprint"`
+	Message     string          `json:"message"`
+	Count       int             `json:"count"`
+	FirstSeen   string          `json:"first_seen"`
+	LastSeen    string          `json:"last_seen"`
+	WrapPaths   []wrapPath      `json:"wrap_paths"`
+	Trace       json.RawMessage `json:"trace"`
+
+	upErr     eris.UnpackedError // representative error
+	first     time.Time          // time of the first entry if all entries have a time
+	last      time.Time          // time of the last entry if all entries have a time
+	untimed   bool               // flag indicating whether any entry of the group doesn't have a time
+	pathCount map[string]int     // number of errors per wrap path
+}
+
+// wrapPath is a sequence of wrap error messages and the number of errors that were wrapped that way.
+type wrapPath struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// report is the result of aggregating the errors of one or more logs.
+type report struct {
+	Entries int      `json:"entries"`
+	Skipped int      `json:"skipped"`
+	Groups  []*group `json:"groups"`
+
+	byFingerprint map[string]*group
+}
+
+func newReport() *report {
+	return &report{Groups: []*group{}, byFingerprint: make(map[string]*group)}
+}
+
+// read adds the errors of a newline-delimited JSON log to the report. Lines without an eris error are counted as
+// skipped. The name of the log is used to refer to entries without a time.
+func (r *report) read(name string, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		upErr, raw, seen, ok := parseEntry([]byte(line))
+		if !ok {
+			r.Skipped++
+			continue
+		}
+		r.Entries++
+		r.add(upErr, raw, seen, fmt.Sprintf("%v:%v", name, lineNum))
+	}
+	return scanner.Err()
+}
+
+// add adds a single error to the group with its fingerprint. The pos is used as the time of errors without a time.
+func (r *report) add(upErr eris.UnpackedError, raw json.RawMessage, seen time.Time, pos string) {
+	fp := eris.Fingerprint(upErr)
+	g, ok := r.byFingerprint[fp]
+	if !ok {
+		g = &group{
+			Fingerprint: fp,
+			Message:     rootMessage(upErr),
+			FirstSeen:   pos,
+			Trace:       raw,
+			upErr:       upErr,
+			first:       seen,
+			pathCount:   make(map[string]int),
+		}
+		r.byFingerprint[fp] = g
+		r.Groups = append(r.Groups, g)
+	}
+	g.Count++
+	g.pathCount[wrapPathOf(upErr)]++
+	g.LastSeen = pos
+	if seen.IsZero() {
+		g.untimed = true
+		return
+	}
+	if seen.Before(g.first) {
+		g.first = seen
+	}
+	if seen.After(g.last) {
+		g.last = seen
+	}
+}
+
+// finish sorts the groups by count and fills in the times and the most common wrap paths (at most top per group).
+// Times are only used if every entry of a group has one, otherwise the positions of the first and last entries in
+// the logs are used.
+func (r *report) finish(top int) {
+	for _, g := range r.Groups {
+		if !g.untimed {
+			g.FirstSeen = g.first.Format(time.RFC3339Nano)
+			g.LastSeen = g.last.Format(time.RFC3339Nano)
+		}
+		g.WrapPaths = nil
+		for path, count := range g.pathCount {
+			g.WrapPaths = append(g.WrapPaths, wrapPath{Path: path, Count: count})
+		}
+		sort.Slice(g.WrapPaths, func(i, j int) bool {
+			if g.WrapPaths[i].Count != g.WrapPaths[j].Count {
+				return g.WrapPaths[i].Count > g.WrapPaths[j].Count
+			}
+			return g.WrapPaths[i].Path < g.WrapPaths[j].Path
+		})
+		if top > 0 && len(g.WrapPaths) > top {
+			g.WrapPaths = g.WrapPaths[:top]
+		}
+	}
+	// the groups are in the order they were first seen, which breaks ties
+	sort.SliceStable(r.Groups, func(i, j int) bool {
+		return r.Groups[i].Count > r.Groups[j].Count
+	})
+}
+
+// parseEntry returns the eris error in a log entry, which is either an eris JSON document itself or has one in a
+// top-level field (e.g. "error"), along with the time of the entry if it has one.
+func parseEntry(line []byte) (eris.UnpackedError, json.RawMessage, time.Time, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return eris.UnpackedError{}, nil, time.Time{}, false
+	}
+	seen := entryTime(fields)
+	if isDoc(fields) {
+		if upErr, ok := parseDoc(line); ok {
+			return upErr, line, seen, true
+		}
+	}
+	keys := make([]string, 0, len(fields))
+	for k, v := range fields {
+		// only objects can be eris documents, so the other fields aren't decoded again
+		if bytes.HasPrefix(v, []byte("{")) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if upErr, ok := parseDoc(fields[k]); ok {
+			return upErr, fields[k], seen, true
+		}
+	}
+	return eris.UnpackedError{}, nil, time.Time{}, false
+}
+
+// isDoc reports whether the fields of a JSON object can be an eris document, i.e. it has a root or external error.
+func isDoc(fields map[string]json.RawMessage) bool {
+	_, root := fields["root"]
+	_, external := fields["external"]
+	return root || external
+}
+
+// parseDoc parses an eris JSON document. Objects without a root or external error aren't eris documents.
+func parseDoc(data []byte) (eris.UnpackedError, bool) {
+	upErr, err := eris.ParseJSON(data, jsonFormat)
+	if err != nil {
+		return eris.UnpackedError{}, false
+	}
+	return upErr, upErr.ErrRoot.Msg != "" || len(upErr.ErrRoot.Stack) > 0 || upErr.ErrExternal != nil
+}
+
+// entryTime returns the time of a log entry or the zero time if it doesn't have one. Times are either strings in
+// RFC 3339 format or numbers of seconds since the Unix epoch (e.g. the "ts" field of zap).
+func entryTime(fields map[string]json.RawMessage) time.Time {
+	for _, k := range timeKeys {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t.UTC()
+			}
+			continue
+		}
+		var secs float64
+		if err := json.Unmarshal(v, &secs); err == nil {
+			return time.Unix(0, int64(secs*float64(time.Second))).UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// rootMessage returns the root error message of an error or the external error message if it doesn't have a root
+// error.
+func rootMessage(upErr eris.UnpackedError) string {
+	if upErr.ErrRoot.Msg == "" && len(upErr.ErrRoot.Stack) == 0 && upErr.ErrExternal != nil {
+		return upErr.ErrExternal.Error()
+	}
+	return upErr.ErrRoot.Msg
+}
+
+// wrapPathOf returns the wrap error messages of an error from the outermost to the innermost wrap error.
+func wrapPathOf(upErr eris.UnpackedError) string {
+	msgs := make([]string, 0, len(upErr.ErrChain))
+	for i := len(upErr.ErrChain) - 1; i >= 0; i-- {
+		if msg := upErr.ErrChain[i].Msg; msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return strings.Join(msgs, ": ")
+}
+
+// formatFrame returns a frame in the eris JSON format.
+func formatFrame(f eris.StackFrame) string {
+	return fmt.Sprintf("%v%v%v%v%v", f.Name, jsonFormat.StackElemSep, f.File, jsonFormat.StackElemSep, f.Line)
+}
+
+// writeText writes the report in a human-readable format with a representative trace for each group.
+func (r *report) writeText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d errors in %d groups (%d lines skipped)\n", r.Entries, len(r.Groups), r.Skipped)
+	for _, g := range r.Groups {
+		fmt.Fprintf(bw, "\n[%v] %v\n", g.Fingerprint, g.Message)
+		fmt.Fprintf(bw, "  count:      %d\n", g.Count)
+		fmt.Fprintf(bw, "  first seen: %v\n", g.FirstSeen)
+		fmt.Fprintf(bw, "  last seen:  %v\n", g.LastSeen)
+		fmt.Fprintf(bw, "  wrap paths:\n")
+		for _, p := range g.WrapPaths {
+			path := p.Path
+			if path == "" {
+				path = "(not wrapped)"
+			}
+			fmt.Fprintf(bw, "    %6d  %v\n", p.Count, path)
+		}
+		fmt.Fprintf(bw, "  trace:\n")
+		for i := len(g.upErr.ErrChain) - 1; i >= 0; i-- {
+			eLink := g.upErr.ErrChain[i]
+			fmt.Fprintf(bw, "    %v\n", eLink.Msg)
+			if eLink.Frame != (eris.StackFrame{}) {
+				fmt.Fprintf(bw, "    \t%v\n", formatFrame(eLink.Frame))
+			}
+		}
+		if root := g.upErr.ErrRoot; root.Msg != "" || len(root.Stack) > 0 {
+			fmt.Fprintf(bw, "    %v\n", root.Msg)
+			for i := len(root.Stack) - 1; i >= 0; i-- {
+				fmt.Fprintf(bw, "    \t%v\n", formatFrame(root.Stack[i]))
+			}
+		}
+		if g.upErr.ErrExternal != nil {
+			fmt.Fprintf(bw, "    %v\n", g.upErr.ErrExternal)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeJSON writes the report as an indented JSON document.
+func (r *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
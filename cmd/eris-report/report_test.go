@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestReport(t *testing.T) {
+	tests := map[string]struct {
+		json bool // flag that enables JSON output
+		top  int  // maximum number of wrap paths per group
+	}{
+		"text": {top: 3},
+		"json": {json: true, top: 3},
+		"top":  {top: 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "app.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			r := newReport()
+			if err := r.read("app.log", f); err != nil {
+				t.Fatalf("read() failed: %v", err)
+			}
+			r.finish(tt.top)
+			var got bytes.Buffer
+			if tt.json {
+				err = r.writeJSON(&got)
+			} else {
+				err = r.writeText(&got)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("report got:\n%s\nwant:\n%s", got.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := map[string]struct {
+		a, b string // log entries
+		same bool   // flag indicating whether the entries have the same fingerprint
+	}{
+		"different lines": {
+			a:    `{"root":{"message":"not found","stack":["main.main:/app/main.go:1","main.find:/app/main.go:2"]}}`,
+			b:    `{"root":{"message":"not found","stack":["main.main:/app/main.go:3","main.find:/app/main.go:4"]}}`,
+			same: true,
+		},
+		"different wrap errors": {
+			a:    `{"root":{"message":"not found","stack":["main.find:/app/main.go:2"]},"wrap":[{"message":"a"}]}`,
+			b:    `{"root":{"message":"not found","stack":["main.find:/app/main.go:2"]},"wrap":[{"message":"b"}]}`,
+			same: true,
+		},
+		"different messages": {
+			a: `{"root":{"message":"not found","stack":["main.find:/app/main.go:2"]}}`,
+			b: `{"root":{"message":"timeout","stack":["main.find:/app/main.go:2"]}}`,
+		},
+		"different functions": {
+			a: `{"root":{"message":"not found","stack":["main.main:/app/main.go:1","main.find:/app/main.go:2"]}}`,
+			b: `{"root":{"message":"not found","stack":["main.worker:/app/main.go:1","main.find:/app/main.go:2"]}}`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, _, _, okA := parseEntry([]byte(tt.a))
+			b, _, _, okB := parseEntry([]byte(tt.b))
+			if !okA || !okB {
+				t.Fatalf("parseEntry() failed")
+			}
+			if same := eris.Fingerprint(a) == eris.Fingerprint(b); same != tt.same {
+				t.Errorf("fingerprint() got same = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestFingerprintOfLoggedError(t *testing.T) {
+	// errors in the logs have the same fingerprint as the logged error (e.g. the fingerprint field of erislogrus) and
+	// as the same root error that wasn't wrapped
+	newErr := func() error {
+		return eris.New("not found")
+	}
+	root := newErr()
+	err := eris.Wrap(eris.WithStack(newErr()), "loading user")
+	data, jerr := json.Marshal(map[string]interface{}{"msg": "request failed", "error": eris.ToJSON(err, true)})
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	upErr, _, _, ok := parseEntry(data)
+	if !ok {
+		t.Fatalf("parseEntry() failed")
+	}
+	if got, want := eris.Fingerprint(upErr), eris.Fingerprint(eris.Unpack(err)); got != want {
+		t.Errorf("Fingerprint() got %v want %v", got, want)
+	}
+	if got, want := eris.Fingerprint(upErr), eris.Fingerprint(eris.Unpack(root)); got != want {
+		t.Errorf("Fingerprint() got %v want %v for the unwrapped error", got, want)
+	}
+}
+
+func TestReadSkipped(t *testing.T) {
+	input := "not json\n{\"msg\":\"no error\"}\n{\"root\":\"not an eris error\"}\n\n"
+	r := newReport()
+	if err := r.read("test.log", strings.NewReader(input)); err != nil {
+		t.Fatalf("read() failed: %v", err)
+	}
+	if r.Entries != 0 || r.Skipped != 3 {
+		t.Errorf("read() got %v entries and %v skipped lines, want 0 and 3", r.Entries, r.Skipped)
+	}
+}
@@ -0,0 +1,123 @@
+// Command eris pretty-prints eris errors in logs.
+//
+// It reads the given files (or the standard input) and detects eris JSON documents (see eris.ToJSON), on their own
+// or nested in JSON log entries, and string traces (see eris.ToString and the '%+v' verb). Each error is printed
+// with one message per line followed by its frames, and any other input is copied unchanged.
+//
+// Usage:
+//
+//   eris [flags] [file ...]
+//
+// The flags are:
+//
+//   -all     show all frames instead of hiding the frames matched by -filter
+//   -color   when to use colors: auto, always, or never (default auto)
+//   -filter  regular expression for function names of frames to hide (default "^(runtime|testing)\.")
+//   -trim    prefix removed from file paths, can be repeated (default the current directory)
+//
+// With -color=auto, colors are used if the standard output is a terminal and NO_COLOR isn't set. Hidden and repeated
+// frames are collapsed into a single line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// trimFlag collects the repeated -trim flags.
+type trimFlag []string
+
+func (f *trimFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *trimFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+var (
+	allFrames  = flag.Bool("all", false, "show all frames instead of hiding the frames matched by -filter")
+	colorMode  = flag.String("color", "auto", "when to use colors: auto, always, or never")
+	filterExpr = flag.String("filter", `^(runtime|testing)\.`, "regular expression for function names of frames to hide")
+	trimPaths  trimFlag
+)
+
+func main() {
+	flag.Var(&trimPaths, "trim", "prefix removed from file paths, can be repeated (default the current directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: eris [flags] [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	p, err := newPrinter()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		if err := p.process(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	exitCode := 0
+	for _, path := range flag.Args() {
+		if err := processFile(p, path, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	os.Exit(exitCode)
+}
+
+// newPrinter returns a printer configured by the flags.
+func newPrinter() (*printer, error) {
+	p := &printer{trim: trimPaths}
+	switch *colorMode {
+	case "auto":
+		p.color = os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	case "always":
+		p.color = true
+	case "never":
+	default:
+		return nil, fmt.Errorf("invalid -color value %q: must be auto, always, or never", *colorMode)
+	}
+	if !*allFrames && *filterExpr != "" {
+		filter, err := regexp.Compile(*filterExpr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -filter value: %v", err)
+		}
+		p.filter = filter
+	}
+	if len(p.trim) == 0 {
+		if wd, err := os.Getwd(); err == nil {
+			p.trim = []string{wd + string(filepath.Separator)}
+		}
+	}
+	return p, nil
+}
+
+// processFile pretty-prints the errors in a single file.
+func processFile(p *printer, path string, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return p.process(f, w)
+}
+
+// isTerminal reports whether a file is a character device like a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// trace is an eris error found in the input.
+type trace struct {
+	prefix   string    // text in front of the error on the same line (e.g. a timestamp or other log fields)
+	sections []section // messages and frames of the error in input order
+	lines    []string  // lines of a string trace
+}
+
+// section is a single error message with its frames.
+type section struct {
+	msg    string
+	root   bool // flag indicating whether this is the root error
+	frames []frame
+}
+
+// frame is a parsed stack frame. The note is the wrap error message that's shown next to the frame if the trace
+// was formatted with FormatOptions.MergeTrace.
+type frame struct {
+	eris.StackFrame
+	note string
+}
+
+// traceFormat is the eris string format with trace that's used to parse string traces. MergeTrace is set, so the
+// messages next to merged frames are parsed as well, and frames without a message are parsed the same way.
+var traceFormat = eris.NewDefaultStringFormat(eris.FormatOptions{WithTrace: true, MergeTrace: true})
+
+// frameRE matches a frame formatted with traceFormat (without the PreStackSep), e.g.
+// "main.main:/app/main.go:12 (wrap msg)". Frames are matched directly, so each line isn't parsed with eris.ParseString.
+var frameRE = regexp.MustCompile(`^(.*?)` + regexp.QuoteMeta(traceFormat.StackElemSep) + `(.*?)` +
+	regexp.QuoteMeta(traceFormat.StackElemSep) + `(\d+)(?: \((.*)\))?$`)
+
+// parseFrame parses a frame formatted by eris (without the PreStackSep).
+func parseFrame(s string) (frame, bool) {
+	m := frameRE.FindStringSubmatch(s)
+	if m == nil {
+		return frame{}, false
+	}
+	line, err := strconv.Atoi(m[3])
+	if err != nil {
+		return frame{}, false
+	}
+	return frame{StackFrame: eris.StackFrame{Name: m[1], File: m[2], Line: line}, note: m[4]}, true
+}
+
+// parseFrameLine parses a line of a string trace that contains a single frame.
+func parseFrameLine(line string) (frame, bool) {
+	if !strings.HasPrefix(line, traceFormat.PreStackSep) {
+		return frame{}, false
+	}
+	return parseFrame(strings.TrimPrefix(line, traceFormat.PreStackSep))
+}
+
+// parseJSONLine returns the eris error in a line that contains an eris JSON document, either on its own or nested
+// in a JSON log entry. The remaining fields of a log entry are kept in the prefix of the trace.
+func parseJSONLine(line string) (*trace, bool) {
+	start := strings.IndexByte(line, '{')
+	if start < 0 {
+		return nil, false
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(line[start:]), &doc); err != nil {
+		return nil, false
+	}
+	t, ok := findJSONTrace(doc)
+	if !ok {
+		return nil, false
+	}
+	t.prefix = strings.TrimSpace(line[:start])
+	if len(doc) > 0 {
+		entry, err := json.Marshal(doc)
+		if err == nil {
+			t.prefix = strings.TrimSpace(t.prefix + " " + string(entry))
+		}
+	}
+	return t, true
+}
+
+// findJSONTrace searches a JSON object for an eris document. A nested document is removed from its parent, so only
+// the other fields are left.
+func findJSONTrace(doc map[string]interface{}) (*trace, bool) {
+	if isErisJSON(doc) {
+		t := jsonTrace(doc)
+		for k := range doc {
+			delete(doc, k)
+		}
+		return t, true
+	}
+	for k, v := range doc {
+		if m, ok := v.(map[string]interface{}); ok && isErisJSON(m) {
+			delete(doc, k)
+			return jsonTrace(m), true
+		}
+	}
+	return nil, false
+}
+
+// isErisJSON reports whether a JSON object looks like the output of eris.ToJSON, i.e. it only has the keys of the
+// eris JSON format and contains at least a root, wrap, or external error.
+func isErisJSON(m map[string]interface{}) bool {
+	found := false
+	for k, v := range m {
+		switch k {
+		case "root":
+			if _, ok := v.(map[string]interface{}); !ok {
+				return false
+			}
+			found = true
+		case "wrap", "external_chain":
+			if _, ok := v.([]interface{}); !ok {
+				return false
+			}
+			found = true
+		case "external", "context":
+			if _, ok := v.(string); !ok {
+				return false
+			}
+			found = found || k == "external"
+		default:
+			return false
+		}
+	}
+	return found
+}
+
+// jsonTrace converts an eris JSON document into a trace with the wrap errors first, followed by the root error and
+// the external error.
+func jsonTrace(doc map[string]interface{}) *trace {
+	t := &trace{}
+	wraps, _ := doc["wrap"].([]interface{})
+	for _, w := range wraps {
+		wrap, _ := w.(map[string]interface{})
+		s := section{msg: jsonString(wrap["message"])}
+		if f, ok := parseFrame(jsonString(wrap["stack"])); ok {
+			s.frames = append(s.frames, f)
+		}
+		t.sections = append(t.sections, s)
+	}
+	if root, ok := doc["root"].(map[string]interface{}); ok {
+		t.sections = append(t.sections, section{
+			msg:    jsonString(root["message"]),
+			root:   true,
+			frames: jsonFrames(root["stack"]),
+		})
+	}
+	if chain, ok := doc["external_chain"].([]interface{}); ok {
+		for _, l := range chain {
+			link, _ := l.(map[string]interface{})
+			msg := jsonString(link["message"])
+			frames := jsonFrames(link["stack"])
+			if msg == "" && len(frames) == 0 {
+				continue
+			}
+			if msg == "" {
+				msg = jsonString(link["type"])
+			}
+			t.sections = append(t.sections, section{msg: msg, frames: frames})
+		}
+	} else if ext, ok := doc["external"].(string); ok {
+		t.sections = append(t.sections, section{msg: ext})
+	}
+	return t
+}
+
+// jsonFrames parses an array of formatted frames.
+func jsonFrames(v interface{}) []frame {
+	arr, _ := v.([]interface{})
+	var frames []frame
+	for _, s := range arr {
+		if f, ok := parseFrame(jsonString(s)); ok {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// jsonString returns a JSON string value or an empty string for any other type.
+func jsonString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// markRoot marks the root error of a string trace as parsed by eris.ParseString or the last error if the trace can't
+// be parsed.
+func markRoot(t *trace) {
+	upErr, err := eris.ParseString(strings.Join(t.lines, traceFormat.ErrorSep), traceFormat)
+	if err == nil {
+		for i, s := range t.sections {
+			if s.msg == upErr.ErrRoot.Msg && len(s.frames) == len(upErr.ErrRoot.Stack) {
+				t.sections[i].root = true
+				return
+			}
+		}
+	}
+	t.sections[len(t.sections)-1].root = true
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ANSI escape codes used for colored output.
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorDim    = "\x1b[2m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+// printer pretty-prints eris errors and copies any other input unchanged.
+type printer struct {
+	color  bool           // flag that enables ANSI colors
+	trim   []string       // prefixes removed from file paths
+	filter *regexp.Regexp // frames with a matching function name are hidden (nil shows all frames)
+}
+
+// process copies lines from r to w and pretty-prints the eris errors it finds on the way.
+//
+// JSON documents are detected on a single line. String traces span multiple lines, so a non-empty line that could be
+// an error message is held back until the next line shows whether it's followed by a frame.
+func (p *printer) process(r io.Reader, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	var (
+		cur  *trace // string trace that's currently parsed
+		held *string
+	)
+	flush := func() {
+		if cur != nil {
+			markRoot(cur)
+			p.print(bw, cur)
+			cur = nil
+		}
+		if held != nil {
+			fmt.Fprintln(bw, *held)
+			held = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if f, ok := parseFrameLine(line); ok && (held != nil || cur != nil) {
+			if held != nil {
+				if cur == nil {
+					cur = &trace{}
+				}
+				cur.sections = append(cur.sections, section{msg: *held})
+				cur.lines = append(cur.lines, *held)
+				held = nil
+			}
+			last := &cur.sections[len(cur.sections)-1]
+			last.frames = append(last.frames, f)
+			cur.lines = append(cur.lines, line)
+			continue
+		}
+		if held != nil {
+			// the held line isn't followed by a frame, so it's not part of a string trace
+			flush()
+		}
+		if t, ok := parseJSONLine(line); ok {
+			flush()
+			p.print(bw, t)
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			flush()
+			fmt.Fprintln(bw, line)
+			continue
+		}
+		held = &line
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// print writes a trace with each error message followed by its frames.
+func (p *printer) print(w io.Writer, t *trace) {
+	if t.prefix != "" {
+		fmt.Fprintln(w, p.paint(colorDim, t.prefix))
+	}
+	for _, s := range t.sections {
+		msgColor := colorBold
+		if s.root {
+			msgColor = colorBold + colorRed
+		}
+		fmt.Fprintln(w, p.paint(msgColor, s.msg))
+		p.printFrames(w, s.frames)
+	}
+}
+
+// printFrames writes the frames of an error. Consecutive frames that are hidden by the filter and repeated frames
+// (e.g. of recursive calls) are collapsed into a single line.
+func (p *printer) printFrames(w io.Writer, frames []frame) {
+	hidden := 0
+	for i := 0; i < len(frames); i++ {
+		f := frames[i]
+		if p.filter != nil && p.filter.MatchString(f.Name) {
+			hidden++
+			continue
+		}
+		if hidden > 0 {
+			fmt.Fprintln(w, traceFormat.PreStackSep+p.paint(colorDim, fmt.Sprintf("... %d hidden %s", hidden, plural(hidden, "frame"))))
+			hidden = 0
+		}
+		fmt.Fprintln(w, traceFormat.PreStackSep+p.formatFrame(f))
+
+		repeated := 0
+		for i+1 < len(frames) && frames[i+1] == f {
+			repeated++
+			i++
+		}
+		if repeated > 0 {
+			fmt.Fprintln(w, traceFormat.PreStackSep+p.paint(colorDim, fmt.Sprintf("... repeated %d more %s", repeated, plural(repeated, "time"))))
+		}
+	}
+	if hidden > 0 {
+		fmt.Fprintln(w, traceFormat.PreStackSep+p.paint(colorDim, fmt.Sprintf("... %d hidden %s", hidden, plural(hidden, "frame"))))
+	}
+}
+
+// formatFrame returns a frame with its function name followed by its trimmed location.
+func (p *printer) formatFrame(f frame) string {
+	str := p.paint(colorCyan, f.Name) + " " + p.paint(colorDim, fmt.Sprintf("%v:%v", p.trimPath(f.File), f.Line))
+	if f.note != "" {
+		str += " " + p.paint(colorYellow, "("+f.note+")")
+	}
+	return str
+}
+
+// trimPath removes the first matching prefix from a file path.
+func (p *printer) trimPath(path string) string {
+	for _, prefix := range p.trim {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix)
+		}
+	}
+	return path
+}
+
+// paint wraps a string in an ANSI color if colors are enabled.
+func (p *printer) paint(color, s string) string {
+	if !p.color || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// plural returns a word with an "s" appended unless n is 1.
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestProcess(t *testing.T) {
+	filter := regexp.MustCompile(`^(runtime|testing)\.`)
+	tests := map[string]struct {
+		input    string   // name of the input file
+		color    bool     // flag that enables colors
+		trim     []string // prefixes removed from file paths
+		noFilter bool     // flag that shows all frames
+	}{
+		"string": {
+			input: "string",
+			trim:  []string{"/app/"},
+		},
+		"json": {
+			input: "json",
+			trim:  []string{"/app/"},
+		},
+		"merged": {
+			input: "merged",
+		},
+		"merged_all": {
+			input:    "merged",
+			noFilter: true,
+		},
+		"color": {
+			input: "string",
+			color: true,
+			trim:  []string{"/app/"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(filepath.Join("testdata", tt.input+".input"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &printer{color: tt.color, trim: tt.trim, filter: filter}
+			if tt.noFilter {
+				p.filter = nil
+			}
+			var got bytes.Buffer
+			if err := p.process(bytes.NewReader(src), &got); err != nil {
+				t.Fatalf("process() failed: %v", err)
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("process() got:\n%s\nwant:\n%s", got.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestProcessPassThrough(t *testing.T) {
+	// input without eris errors is copied unchanged
+	input := "line 1\n\tindented line\n{\"msg\":\"json\"}\n\n\tmain.main:/app/main.go:1\nlast line\n"
+	p := &printer{}
+	var got strings.Builder
+	if err := p.process(strings.NewReader(input), &got); err != nil {
+		t.Fatalf("process() failed: %v", err)
+	}
+	if got.String() != input {
+		t.Errorf("process() got %q, want %q", got.String(), input)
+	}
+}
+
+func TestParseFrame(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		name  string
+		file  string
+		line  int
+		note  string
+		ok    bool
+	}{
+		"frame": {
+			input: "main.main:/app/main.go:12",
+			name:  "main.main",
+			file:  "/app/main.go",
+			line:  12,
+			ok:    true,
+		},
+		"method": {
+			input: "github.com/a/b.(*T).m:C:/src/b.go:7",
+			name:  "github.com/a/b.(*T).m",
+			file:  "C:/src/b.go",
+			line:  7,
+			ok:    true,
+		},
+		"merged": {
+			input: "main.main:/app/main.go:12 (wrap: msg)",
+			name:  "main.main",
+			file:  "/app/main.go",
+			line:  12,
+			note:  "wrap: msg",
+			ok:    true,
+		},
+		"no line": {
+			input: "main.main:/app/main.go",
+		},
+		"message": {
+			input: "error: not found",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, ok := parseFrame(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("parseFrame() ok = %v, want %v", ok, tt.ok)
+			}
+			if f.Name != tt.name || f.File != tt.file || f.Line != tt.line || f.note != tt.note {
+				t.Errorf("parseFrame() got %+v, want {%v %v %v %v}", f, tt.name, tt.file, tt.line, tt.note)
+			}
+		})
+	}
+}
+
+func TestMarkRoot(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		root  string // expected message of the root error
+	}{
+		"root error": {
+			input: "loading user\n\tmain.handler:/app/main.go:18\nnot found\n\tmain.handler:/app/main.go:18\n\tmain.find:/app/main.go:12\n",
+			root:  "not found",
+		},
+		"wrap error without message": {
+			input: "loading user\n\tmain.handler:/app/main.go:19\n\tmain.handler:/app/main.go:18\nnot found\n\tmain.main:/app/main.go:25\n\tmain.handler:/app/main.go:19\n\tmain.handler:/app/main.go:18\n\tmain.find:/app/main.go:12\n",
+			root:  "not found",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &printer{color: true}
+			var got strings.Builder
+			if err := p.process(strings.NewReader(tt.input), &got); err != nil {
+				t.Fatalf("process() failed: %v", err)
+			}
+			if want := colorBold + colorRed + tt.root + colorReset + "\n"; !strings.Contains(got.String(), want) || strings.Count(got.String(), colorRed) != 1 {
+				t.Errorf("process() got root error in\n%q\nwant %q", got.String(), tt.root)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package eris
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ContextExtractor returns a request-scoped value from a context and reports whether the context contains it.
+type ContextExtractor func(ctx context.Context) (interface{}, bool)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = make(map[string]ContextExtractor)
+)
+
+// RegisterContextExtractor registers an extractor for a field of errors created with NewCtx and WrapCtx (e.g. a
+// request ID or tenant). Registering an extractor for an existing field replaces it, and a nil extractor removes it.
+func RegisterContextExtractor(field string, extractor ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	if extractor == nil {
+		delete(extractors, field)
+		return
+	}
+	extractors[field] = extractor
+}
+
+// contextFields returns the fields of all registered extractors for a context.
+func contextFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	for field, extract := range registeredExtractors() {
+		if v, ok := extract(ctx); ok {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			fields[field] = v
+		}
+	}
+	return fields
+}
+
+// registeredExtractors returns a copy of the registered context extractors. The extractors are called without holding
+// the lock, so they can register extractors or create errors themselves.
+func registeredExtractors() map[string]ContextExtractor {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	copied := make(map[string]ContextExtractor, len(extractors))
+	for field, extract := range extractors {
+		copied[field] = extract
+	}
+	return copied
+}
+
+// NewCtx creates a new root error with a static message and the fields of all registered context extractors (see
+// RegisterContextExtractor). If the context is already done, its error is recorded as well.
+func NewCtx(ctx context.Context, msg string) error {
+	stack := callers(3)
+	err := &rootError{
+		global: stack.isGlobal(),
+		msg:    msg,
+		stack:  stack,
+	}
+	err.fields = contextFields(ctx)
+	err.ctxErr = ctxErr(ctx)
+	return err
+}
+
+// WrapCtx adds additional context to an error along with the fields of all registered context extractors (see
+// RegisterContextExtractor). If the context is already done, its error is recorded as well.
+//
+// This method is otherwise the same as Wrap.
+func WrapCtx(ctx context.Context, err error, msg string) error {
+	err = wrap(err, fmt.Sprint(msg))
+	if m := metaOf(err); m != nil {
+		// the error was just created by wrap, so it's safe to modify
+		m.fields = contextFields(ctx)
+		m.ctxErr = ctxErr(ctx)
+	}
+	return err
+}
+
+// ctxErr returns the error of a context or nil if the context isn't done.
+func ctxErr(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// Fields returns the fields of all errors in err's chain. If multiple errors have the same field, the value that's
+// closest to the top of the chain is used.
+func Fields(err error) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for err != nil {
+		if m := metaOf(err); m != nil {
+			for k, v := range m.fields {
+				if _, exists := fields[k]; !exists {
+					fields[k] = v
+				}
+			}
+		}
+		err = Unwrap(err)
+	}
+	return fields
+}
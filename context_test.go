@@ -0,0 +1,150 @@
+package eris_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+type ctxKey string
+
+func TestContextFields(t *testing.T) {
+	eris.RegisterContextExtractor("request_id", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(ctxKey("request_id")).(string)
+		return v, ok
+	})
+	eris.RegisterContextExtractor("tenant", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(ctxKey("tenant")).(string)
+		return v, ok
+	})
+	defer eris.RegisterContextExtractor("request_id", nil)
+	defer eris.RegisterContextExtractor("tenant", nil)
+
+	rootCtx := context.WithValue(context.Background(), ctxKey("request_id"), "req-1")
+	wrapCtx := context.WithValue(rootCtx, ctxKey("tenant"), "acme")
+	tests := map[string]struct {
+		input  error
+		fields map[string]interface{}
+		msg    string
+	}{
+		"root error": {
+			input:  eris.NewCtx(rootCtx, "root error"),
+			fields: map[string]interface{}{"request_id": "req-1"},
+			msg:    "root error",
+		},
+		"wrapped root error": {
+			input:  eris.WrapCtx(wrapCtx, eris.NewCtx(rootCtx, "root error"), "additional context"),
+			fields: map[string]interface{}{"request_id": "req-1", "tenant": "acme"},
+			msg:    "additional context: root error",
+		},
+		"wrapped external error": {
+			input:  eris.WrapCtx(wrapCtx, errors.New("external error"), "additional context"),
+			fields: map[string]interface{}{"request_id": "req-1", "tenant": "acme"},
+			msg:    "additional context: external error",
+		},
+		"outer fields take precedence": {
+			input: eris.WrapCtx(
+				context.WithValue(context.Background(), ctxKey("request_id"), "req-2"),
+				eris.NewCtx(rootCtx, "root error"),
+				"additional context",
+			),
+			fields: map[string]interface{}{"request_id": "req-2"},
+			msg:    "additional context: root error",
+		},
+		"context without values": {
+			input:  eris.NewCtx(context.Background(), "root error"),
+			fields: map[string]interface{}{},
+			msg:    "root error",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.Fields(tt.input); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("Fields() got { %v } want { %v }", got, tt.fields)
+			}
+			if got := tt.input.Error(); got != tt.msg {
+				t.Errorf("Error() got { %v } want { %v }", got, tt.msg)
+			}
+		})
+	}
+
+	if err := eris.WrapCtx(rootCtx, nil, "additional context"); err != nil {
+		t.Errorf("WrapCtx() got { %v } want { nil }", err)
+	}
+
+	err := eris.WrapCtx(wrapCtx, eris.NewCtx(rootCtx, "root error"), "additional context")
+	result, _ := json.Marshal(eris.ToJSON(err, false))
+	want := `{"root":{"fields":{"request_id":"req-1"},"message":"root error"},"wrap":[{"fields":{"request_id":"req-1","tenant":"acme"},"message":"additional context"}]}`
+	if got := string(result); got != want {
+		t.Errorf("ToJSON() got %v want %v", got, want)
+	}
+}
+
+func TestNestedContextExtractor(t *testing.T) {
+	// extractors can register other extractors without a deadlock
+	eris.RegisterContextExtractor("request_id", func(ctx context.Context) (interface{}, bool) {
+		eris.RegisterContextExtractor("nested", nil)
+		return "req-1", true
+	})
+	defer eris.RegisterContextExtractor("request_id", nil)
+
+	done := make(chan error)
+	go func() {
+		done <- eris.NewCtx(context.Background(), "root error")
+	}()
+	select {
+	case err := <-done:
+		if got, want := eris.Fields(err), map[string]interface{}{"request_id": "req-1"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Fields() got { %v } want { %v }", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("NewCtx() didn't return after registering an extractor in an extractor")
+	}
+}
+
+func TestContextErr(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := map[string]struct {
+		input  error
+		output error
+	}{
+		"no context error": {
+			input:  eris.Wrap(eris.New("root error"), "additional context"),
+			output: nil,
+		},
+		"wrapped context.Canceled": {
+			input:  eris.Wrap(context.Canceled, "additional context"),
+			output: context.Canceled,
+		},
+		"wrapped context.DeadlineExceeded": {
+			input:  eris.Wrap(eris.Wrap(context.DeadlineExceeded, "additional context"), "even more context"),
+			output: context.DeadlineExceeded,
+		},
+		"created with a canceled context": {
+			input:  eris.NewCtx(canceledCtx, "root error"),
+			output: context.Canceled,
+		},
+		"wrapped with a canceled context": {
+			input:  eris.WrapCtx(canceledCtx, eris.New("root error"), "additional context"),
+			output: context.Canceled,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.Unpack(tt.input).ErrContext; got != tt.output {
+				t.Errorf("Unpack() got context error { %v } want { %v }", got, tt.output)
+			}
+			_, exists := eris.ToJSON(tt.input, false)["context"]
+			if exists != (tt.output != nil) {
+				t.Errorf("ToJSON() got context field { %v } want { %v }", exists, tt.output != nil)
+			}
+		})
+	}
+}
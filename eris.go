@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"time"
 )
 
 // New creates a new root error with a static message.
@@ -27,6 +28,45 @@ func Errorf(format string, args ...interface{}) error {
 	}
 }
 
+// NewPublic creates a new root error with a static message that's safe to show to users. The message is used as
+// both the error message and the public message of the error.
+func NewPublic(msg string) error {
+	stack := callers(3)
+	return &rootError{
+		global: stack.isGlobal(),
+		msg:    msg,
+		stack:  stack,
+		meta:   meta{public: msg},
+	}
+}
+
+// WithPublicMessage returns a copy of an error with a message that's safe to show to users. The public message
+// doesn't change the error message or the trace, which still contain all internal context.
+//
+// For root and wrap errors, the public message is attached to a copy of the error, so the original error is left
+// unchanged. For external types, this method creates a new root error without a message that wraps the external
+// error. If err is nil, WithPublicMessage returns nil.
+func WithPublicMessage(err error, msg string) error {
+	return withMeta(err, func(m *meta) {
+		m.public = msg
+	})
+}
+
+// DefaultPublicMessage is the public message returned by PublicMessage for errors without a public message.
+var DefaultPublicMessage = "internal error"
+
+// PublicMessage returns the public message that's closest to the top of err's chain. If no error in the chain has a
+// public message, it returns DefaultPublicMessage.
+func PublicMessage(err error) string {
+	for err != nil {
+		if m := metaOf(err); m != nil && m.public != "" {
+			return m.public
+		}
+		err = Unwrap(err)
+	}
+	return DefaultPublicMessage
+}
+
 // Wrap adds additional context to all error types while maintaining the type of the original error.
 //
 // This method behaves differently for each error type. For root errors, the stack trace is reset to the current
@@ -46,6 +86,24 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	return wrap(err, fmt.Sprintf(format, args...))
 }
 
+// WithStack adds the caller's frame to an error without adding a message.
+//
+// This is useful for recording that an error passed through a function when there's no additional context to add.
+// The frame is added to the stack the same way as in Wrap, but the new wrap error doesn't have a message, so it's
+// skipped in the error message and only shows up in the trace.
+//
+//go:noinline
+func WithStack(err error) error {
+	return wrap(err, "")
+}
+
+// Trace is an alias for WithStack.
+//
+//go:noinline
+func Trace(err error) error {
+	return wrap(err, "")
+}
+
 func wrap(err error, msg string) error {
 	if err == nil {
 		return nil
@@ -64,18 +122,17 @@ func wrap(err error, msg string) error {
 				global: e.global,
 				msg:    e.msg,
 				stack:  stack,
+				meta:   e.meta,
 			}
-		} else {
-			// insert the frame into the stack
-			e.stack.insertPC(*stack)
 		}
 	case *wrapError:
-		// insert the frame into the stack
-		if root, ok := Cause(err).(*rootError); ok {
-			root.stack.insertPC(*stack)
-		}
+		// nothing to do, the frame is merged into the root error stack when the chain is unpacked
 	default:
-		// return a new root error that wraps the external error
+		// return a new root error that wraps the external error and reuses its stack if it has one
+		if origin := originStack(e); origin != nil {
+			origin.insertPC(*stack)
+			stack = origin
+		}
 		return &rootError{
 			msg:   msg,
 			ext:   e,
@@ -83,10 +140,12 @@ func wrap(err error, msg string) error {
 		}
 	}
 
+	// the root error stack is never changed, so the same error can be wrapped concurrently
 	return &wrapError{
 		msg:   msg,
 		err:   err,
 		frame: frame,
+		stack: stack,
 	}
 }
 
@@ -194,11 +253,30 @@ func StackFrames(err error) []uintptr {
 	return []uintptr{}
 }
 
+// MergedStackFrames returns the full trace of an error in the form of a program counter slice. For wrap errors, this is
+// the root error stack with the frames of the error and every wrap error below it, i.e. the same stack that's shown
+// for the root error when the error is formatted. For root errors, it's the same as StackFrames.
+func MergedStackFrames(err error) []uintptr {
+	var wrapPCs []*stack // callers of each wrap error in the chain, outermost first
+	for {
+		switch e := err.(type) {
+		case *wrapError:
+			wrapPCs = append(wrapPCs, e.stack)
+			err = e.err
+		case *rootError:
+			return *mergeStack(e.stack, wrapPCs)
+		default:
+			return []uintptr{}
+		}
+	}
+}
+
 type rootError struct {
 	global bool   // flag indicating whether the error was declared globally
 	msg    string // root error message
 	ext    error  // error type for wrapping external errors
 	stack  *stack // root error stack trace
+	meta          // metadata attached to the error
 }
 
 func (e *rootError) Error() string {
@@ -210,6 +288,11 @@ func (e *rootError) Format(s fmt.State, verb rune) {
 }
 
 func (e *rootError) Is(target error) bool {
+	if e.msg == "" {
+		// root errors without a message (e.g. marked external errors) only match themselves, the external error is
+		// compared when the chain is unwrapped
+		return e == target
+	}
 	if err, ok := target.(*rootError); ok {
 		return e.msg == err.msg
 	}
@@ -234,13 +317,16 @@ func (e *rootError) Unwrap() error {
 // StackFrames returns the trace of a root error in the form of a program counter slice.
 // This method is currently called by an external error tracing library (Sentry).
 func (e *rootError) StackFrames() []uintptr {
-	return *e.stack
+	// return a copy, so the stack of the error can't be changed by the caller
+	return *e.stack.clone()
 }
 
 type wrapError struct {
 	msg   string // wrap error message
 	err   error  // error type representing the next error in the chain
 	frame *frame // wrap error stack frame
+	stack *stack // callers of the wrap error used to insert its frame into the root error stack
+	meta         // metadata attached to the error
 }
 
 func (e *wrapError) Error() string {
@@ -252,6 +338,10 @@ func (e *wrapError) Format(s fmt.State, verb rune) {
 }
 
 func (e *wrapError) Is(target error) bool {
+	if e.msg == "" {
+		// wrap errors without a message (see WithStack) never match, so the comparison continues down the chain
+		return false
+	}
 	if err, ok := target.(*wrapError); ok {
 		return e.msg == err.msg
 	}
@@ -279,6 +369,52 @@ func (e *wrapError) StackFrames() []uintptr {
 	return []uintptr{e.frame.pc()}
 }
 
+// meta is the metadata attached to root and wrap errors.
+type meta struct {
+	public     string                 // message that's safe to show to users
+	retry      retryClass             // flag indicating whether the error can be retried
+	retryAfter time.Duration          // minimum delay before retrying
+	fields     map[string]interface{} // request-scoped values from a context
+	ctxErr     error                  // error of the context that was done when the error was created
+}
+
+// metaOf returns the metadata of a root or wrap error or nil for any other error type.
+func metaOf(err error) *meta {
+	switch e := err.(type) {
+	case *rootError:
+		return &e.meta
+	case *wrapError:
+		return &e.meta
+	}
+	return nil
+}
+
+// withMeta returns a copy of a root or wrap error with updated metadata, so the original error is left unchanged.
+// External errors are wrapped in a new root error without a message, which only matches itself in Is, so marked
+// sentinel errors don't match other marked errors.
+func withMeta(err error, update func(m *meta)) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *rootError:
+		c := *e
+		update(&c.meta)
+		return &c
+	case *wrapError:
+		c := *e
+		update(&c.meta)
+		return &c
+	default:
+		// callers(4) skips runtime.Callers, stack.callers, this method, and the exported caller
+		root := &rootError{
+			ext:   e,
+			stack: callers(4),
+		}
+		update(&root.meta)
+		return root
+	}
+}
+
 func printError(err error, s fmt.State, verb rune) {
 	var withTrace bool
 	switch verb {
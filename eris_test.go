@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/rotisserie/eris"
@@ -349,6 +350,27 @@ func TestErrorIs(t *testing.T) {
 			compare: nil,
 			output:  true,
 		},
+		"comparing errors with stack": {
+			cause:   eris.WithStack(eris.New("root error")),
+			compare: eris.WithStack(eris.New("other error")),
+			output:  false,
+		},
+		"comparing external errors with stack": {
+			cause:   eris.WithStack(externalErr),
+			compare: eris.Trace(errors.New("other error")),
+			output:  false,
+		},
+		"comparing against external error with stack": {
+			cause:   eris.Wrap(eris.WithStack(eris.New("root error")), "additional context"),
+			compare: eris.WithStack(errors.New("root error")),
+			output:  false,
+		},
+		"comparing external error with stack against itself": {
+			cause:   eris.WithStack(externalErr),
+			input:   []string{"additional context"},
+			compare: externalErr,
+			output:  true,
+		},
 	}
 
 	for desc, tc := range tests {
@@ -744,6 +766,272 @@ func TestStackFrames(t *testing.T) {
 			if tc.isWrapErr && !reflect.DeepEqual(getFrameFromLink(uErr.ErrChain[0]), sFrames) {
 				t.Errorf("%v: expected { %v } got { %v }", desc, getFrameFromLink(uErr.ErrChain[0]), sFrames)
 			}
+			// the merged stack is the root error stack with the frames of the wrap errors
+			mFrames := eris.Stack(getFrames(eris.MergedStackFrames(err)))
+			if !reflect.DeepEqual(uErr.ErrRoot.Stack, mFrames) {
+				t.Errorf("%v: expected merged stack { %v } got { %v }", desc, uErr.ErrRoot.Stack, mFrames)
+			}
+		})
+	}
+}
+
+func newRootError() error {
+	return eris.New("root error")
+}
+
+func TestMergedStackFrames(t *testing.T) {
+	// the frames of wrap errors are part of the stack of the outermost error, but not of the root error
+	root := newRootError()
+	err := eris.Wrap(root, "additional context")
+	err = eris.Wrap(err, "even more context")
+
+	uErr := eris.Unpack(err)
+	frames := eris.Stack(getFrames(eris.MergedStackFrames(err)))
+	for _, link := range uErr.ErrChain {
+		if !containsFrame(frames, link.Frame) {
+			t.Errorf("MergedStackFrames() got { %v } want the wrap frame { %v }", frames, link.Frame)
+		}
+	}
+	if !reflect.DeepEqual(frames, uErr.ErrRoot.Stack) {
+		t.Errorf("MergedStackFrames() got { %v } want { %v }", frames, uErr.ErrRoot.Stack)
+	}
+	rootFrames := eris.Stack(getFrames(eris.MergedStackFrames(root)))
+	if len(rootFrames) >= len(frames) {
+		t.Errorf("MergedStackFrames() of the root error got { %v } want it without the wrap frames", rootFrames)
+	}
+}
+
+func TestPublicMessage(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		output string // expected public message
+		msg    string // expected error message
+	}{
+		"root error without public message": {
+			input:  eris.New("root error"),
+			output: eris.DefaultPublicMessage,
+			msg:    "root error",
+		},
+		"public root error": {
+			input:  eris.Wrap(eris.NewPublic("user not found"), "failed to query table users"),
+			output: "user not found",
+			msg:    "failed to query table users: user not found",
+		},
+		"public message on root error": {
+			input:  eris.Wrap(eris.WithPublicMessage(eris.New("root error"), "public error"), "additional context"),
+			output: "public error",
+			msg:    "additional context: root error",
+		},
+		"public message on global root error": {
+			input:  eris.Wrap(eris.WithPublicMessage(globalErr, "public error"), "additional context"),
+			output: "public error",
+			msg:    "additional context: global error",
+		},
+		"nearest public message": {
+			input:  eris.WithPublicMessage(eris.Wrap(eris.NewPublic("user not found"), "additional context"), "user not available"),
+			output: "user not available",
+			msg:    "additional context: user not found",
+		},
+		"public message on external error": {
+			input:  eris.Wrap(eris.WithPublicMessage(errors.New("external error"), "public error"), "additional context"),
+			output: "public error",
+			msg:    "additional context: external error",
+		},
+		"external error": {
+			input:  errors.New("external error"),
+			output: eris.DefaultPublicMessage,
+			msg:    "external error",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.PublicMessage(tt.input); got != tt.output {
+				t.Errorf("PublicMessage() got { %v } want { %v }", got, tt.output)
+			}
+			if got := tt.input.Error(); got != tt.msg {
+				t.Errorf("Error() got { %v } want { %v }", got, tt.msg)
+			}
+		})
+	}
+
+	if err := eris.WithPublicMessage(nil, "public error"); err != nil {
+		t.Errorf("WithPublicMessage() got { %v } want { nil }", err)
+	}
+
+	// the original error must not be changed
+	err := eris.New("root error")
+	_ = eris.WithPublicMessage(err, "public error")
+	if got := eris.PublicMessage(err); got != eris.DefaultPublicMessage {
+		t.Errorf("PublicMessage() got { %v } want { %v }", got, eris.DefaultPublicMessage)
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		msg    string // expected error message
+		frames int    // expected number of frames in the wrap chain
+	}{
+		"root error": {
+			input:  eris.WithStack(eris.New("root error")),
+			msg:    "root error",
+			frames: 1,
+		},
+		"wrapped root error": {
+			input:  eris.Wrap(eris.Trace(eris.Wrap(eris.New("root error"), "additional context")), "even more context"),
+			msg:    "even more context: additional context: root error",
+			frames: 3,
+		},
+		"global root error": {
+			input:  eris.Trace(globalErr),
+			msg:    "global error",
+			frames: 1,
+		},
+		"external error": {
+			input:  eris.Wrap(eris.WithStack(errors.New("external error")), "additional context"),
+			msg:    "additional context: external error",
+			frames: 1,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := tt.input.Error(); got != tt.msg {
+				t.Errorf("Error() got { %v } want { %v }", got, tt.msg)
+			}
+			upErr := eris.Unpack(tt.input)
+			if got := len(upErr.ErrChain); got != tt.frames {
+				t.Errorf("Unpack() got %v wrap frames want %v", got, tt.frames)
+			}
+			for _, eLink := range upErr.ErrChain {
+				if len(upErr.ErrRoot.Stack) > 0 && !containsFrame(upErr.ErrRoot.Stack, eLink.Frame) {
+					t.Errorf("Unpack() wrap frame %v is missing from the root stack", eLink.Frame)
+				}
+			}
+			withTrace := fmt.Sprintf("%+v", tt.input)
+			if strings.Contains(withTrace, "\n\n") {
+				t.Errorf("%%+v got an empty line in { %v }", withTrace)
+			}
+		})
+	}
+
+	if err := eris.WithStack(nil); err != nil {
+		t.Errorf("WithStack() got { %v } want { nil }", err)
+	}
+}
+
+func containsFrame(stack eris.Stack, f eris.StackFrame) bool {
+	for _, sf := range stack {
+		if sf == f {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConcurrentWrapping(t *testing.T) {
+	tests := map[string]struct {
+		input error
+	}{
+		"root error": {
+			input: eris.New("root error"),
+		},
+		"wrapped root error": {
+			input: eris.Wrap(eris.New("root error"), "additional context"),
+		},
+		"wrapped external error": {
+			input: eris.Wrap(errors.New("external error"), "additional context"),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			want := eris.ToString(tt.input, true)
+			rootStack := eris.Unpack(tt.input).ErrRoot.Stack
+
+			const n = 50
+			errs := make([]error, n)
+			var wg sync.WaitGroup
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = wrapInGoroutine(tt.input, i)
+				}(i)
+			}
+			wg.Wait()
+
+			for i, err := range errs {
+				upErr := eris.Unpack(err)
+				if got := upErr.ErrChain[len(upErr.ErrChain)-1].Msg; got != fmt.Sprintf("goroutine %v", i) {
+					t.Errorf("Wrapf() got message { %v } want { goroutine %v }", got, i)
+				}
+				// each trace contains the original stack plus the frame of its own goroutine
+				if got := len(upErr.ErrRoot.Stack); got != len(rootStack)+1 {
+					t.Errorf("Wrapf() got %v root frames want %v: %v", got, len(rootStack)+1, upErr.ErrRoot.Stack)
+				}
+			}
+			if got := eris.ToString(tt.input, true); got != want {
+				t.Errorf("ToString() of the original error changed from { %v } to { %v }", want, got)
+			}
+		})
+	}
+}
+
+// wrapInGoroutine wraps an error and formats the result, so the goroutine's frame is added to the root stack.
+func wrapInGoroutine(err error, i int) error {
+	err = eris.Wrapf(err, "goroutine %v", i)
+	_ = eris.ToString(err, true)
+	return err
+}
+
+func TestErrorSnapshots(t *testing.T) {
+	tests := map[string]struct {
+		input error
+	}{
+		"root error": {
+			input: eris.New("root error"),
+		},
+		"wrapped root error": {
+			input: eris.Wrap(eris.New("root error"), "additional context"),
+		},
+		"global root error": {
+			input: globalErr,
+		},
+		"wrapped global root error": {
+			input: eris.Wrap(globalErr, "additional context"),
+		},
+		"wrapped root error with merged frames": {
+			input: eris.Wrap(eris.Wrap(newRootError(), "additional context"), "even more context"),
+		},
+		"wrapped external error": {
+			input: eris.Wrap(errors.New("external error"), "additional context"),
+		},
+		"public root error": {
+			input: eris.WithPublicMessage(eris.New("root error"), "public error"),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			want := fmt.Sprintf("%+v", tt.input)
+			wantUnpacked := eris.Unpack(tt.input)
+			wantFrames := eris.StackFrames(tt.input)
+
+			// wrap, annotate, and format the error in a different function
+			wrapped := wrapInGoroutine(eris.Trace(tt.input), 0)
+			_ = eris.WithPublicMessage(eris.MarkRetryable(wrapped), "public error")
+			_ = fmt.Sprintf("%+v", wrapped)
+			if frames := eris.StackFrames(tt.input); len(frames) > 0 {
+				frames[0] = 0
+			}
+
+			if got := fmt.Sprintf("%+v", tt.input); got != want {
+				t.Errorf("%%+v of the original error changed from { %v } to { %v }", want, got)
+			}
+			if got := eris.Unpack(tt.input); !reflect.DeepEqual(got, wantUnpacked) {
+				t.Errorf("Unpack() of the original error changed from { %+v } to { %+v }", wantUnpacked, got)
+			}
+			if got := eris.StackFrames(tt.input); !reflect.DeepEqual(got, wantFrames) {
+				t.Errorf("StackFrames() of the original error changed from { %v } to { %v }", wantFrames, got)
+			}
 		})
 	}
 }
@@ -0,0 +1,47 @@
+// Package eriscockroachdb imports the stack traces of errors created with github.com/cockroachdb/errors.
+package eriscockroachdb
+
+import (
+	"github.com/cockroachdb/errors/errbase"
+	"github.com/rotisserie/eris"
+)
+
+// stackTracer is implemented by the wrappers that cockroachdb/errors uses to record stack traces.
+type stackTracer interface {
+	StackTrace() errbase.StackTrace
+}
+
+// StackFunc returns the stack of an error created with cockroachdb/errors as a program counter slice. It can be
+// registered with eris.RegisterStackFunc.
+//
+// Errors that were decoded from another process don't have program counters, so no stack is returned for them.
+func StackFunc(err error) ([]uintptr, bool) {
+	st, ok := err.(stackTracer)
+	if !ok {
+		return nil, false
+	}
+	trace := st.StackTrace()
+	pcs := make([]uintptr, len(trace))
+	for i, f := range trace {
+		pcs[i] = uintptr(f)
+	}
+	return pcs, len(pcs) > 0
+}
+
+// Stack returns the stack of the innermost error in err's chain that was created with cockroachdb/errors or nil if
+// there isn't one.
+func Stack(err error) eris.Stack {
+	var stack eris.Stack
+	for ; err != nil; err = eris.Unwrap(err) {
+		if pcs, ok := StackFunc(err); ok {
+			stack = eris.NewStack(pcs)
+		}
+	}
+	return stack
+}
+
+// Register registers StackFunc, so errors created with cockroachdb/errors keep their original stack when they're
+// wrapped with eris.
+func Register() {
+	eris.RegisterStackFunc("cockroachdb/errors", StackFunc)
+}
@@ -0,0 +1,23 @@
+module github.com/rotisserie/eris/eriscockroachdb
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/cockroachdb/errors v1.12.0
+	github.com/rotisserie/eris v0.5.4
+)
+
+require (
+	github.com/cockroachdb/logtags v0.0.0-20230118201751-21c54148d20b // indirect
+	github.com/cockroachdb/redact v1.1.5 // indirect
+	github.com/getsentry/sentry-go v0.27.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/rogpeppe/go-internal v1.9.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+)
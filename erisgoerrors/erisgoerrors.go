@@ -0,0 +1,36 @@
+// Package erisgoerrors imports the stack traces of errors created with github.com/go-errors/errors.
+package erisgoerrors
+
+import (
+	goerrors "github.com/go-errors/errors"
+	"github.com/rotisserie/eris"
+)
+
+// StackFunc returns the stack of an error created with go-errors as a program counter slice. It can be registered
+// with eris.RegisterStackFunc.
+func StackFunc(err error) ([]uintptr, bool) {
+	e, ok := err.(*goerrors.Error)
+	if !ok {
+		return nil, false
+	}
+	pcs := e.Callers()
+	return pcs, len(pcs) > 0
+}
+
+// Stack returns the stack of the innermost error in err's chain that was created with go-errors or nil if there
+// isn't one.
+func Stack(err error) eris.Stack {
+	var stack eris.Stack
+	for ; err != nil; err = eris.Unwrap(err) {
+		if pcs, ok := StackFunc(err); ok {
+			stack = eris.NewStack(pcs)
+		}
+	}
+	return stack
+}
+
+// Register registers StackFunc, so errors created with go-errors keep their original stack when they're wrapped with
+// eris.
+func Register() {
+	eris.RegisterStackFunc("go-errors", StackFunc)
+}
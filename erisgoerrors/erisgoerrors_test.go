@@ -0,0 +1,85 @@
+package erisgoerrors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	goerrors "github.com/go-errors/errors"
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erisgoerrors"
+)
+
+func newError() error {
+	return goerrors.Errorf("external error")
+}
+
+func TestStack(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		output string // name of the innermost frame (empty if there's no stack)
+	}{
+		"nil error": {
+			input:  nil,
+			output: "",
+		},
+		"error without stack": {
+			input:  fmt.Errorf("external error"),
+			output: "",
+		},
+		"error with stack": {
+			input:  newError(),
+			output: "erisgoerrors_test.newError",
+		},
+		"wrapped error with stack": {
+			input:  goerrors.WrapPrefix(newError(), "additional context", 0),
+			output: "erisgoerrors_test.newError",
+		},
+		"standard wrapped error with stack": {
+			input:  fmt.Errorf("additional context: %w", newError()),
+			output: "erisgoerrors_test.newError",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			stack := erisgoerrors.Stack(tt.input)
+			if tt.output == "" {
+				if stack != nil {
+					t.Errorf("Stack() got { %v } want { nil }", stack)
+				}
+				return
+			}
+			if len(stack) == 0 || stack[0].Name != tt.output {
+				t.Fatalf("Stack() got { %v } want innermost frame { %v }", stack, tt.output)
+			}
+			for _, f := range stack {
+				if strings.HasPrefix(f.Name, "runtime.") {
+					t.Errorf("Stack() got runtime frame { %v }", f)
+				}
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	erisgoerrors.Register()
+	defer eris.RegisterStackFunc("go-errors", nil)
+
+	err := eris.Wrap(goerrors.WrapPrefix(newError(), "additional context", 0), "even more context")
+	stack := eris.Unpack(err).ErrRoot.Stack
+	if len(stack) == 0 || stack[0].Name != "erisgoerrors_test.newError" {
+		t.Errorf("Wrap() got root stack { %v } want the stack of the external error", stack)
+	}
+	if str := eris.ToString(err, true); !strings.Contains(str, "erisgoerrors_test.newError") {
+		t.Errorf("ToString() got { %v } without the stack of the external error", str)
+	}
+}
+
+func TestBuiltInInterfaces(t *testing.T) {
+	// the stack is reused without registering StackFunc because eris recognizes the interface of the errors
+	err := eris.Wrap(newError(), "additional context")
+	stack := eris.Unpack(err).ErrRoot.Stack
+	if len(stack) == 0 || stack[0].Name != "erisgoerrors_test.newError" {
+		t.Errorf("Wrap() got root stack { %v } want the stack of the external error", stack)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/rotisserie/eris/erisgoerrors
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/go-errors/errors v1.5.1
+	github.com/rotisserie/eris v0.5.4
+)
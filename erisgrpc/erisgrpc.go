@@ -0,0 +1,226 @@
+// Package erisgrpc converts eris errors to and from gRPC statuses via client and server interceptors.
+package erisgrpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rotisserie/eris"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Options defines how errors are converted into gRPC statuses.
+type Options struct {
+	// WithDebugInfo is a flag that adds the error trace to statuses as errdetails.DebugInfo. It shouldn't be enabled
+	// for services with untrusted clients.
+	WithDebugInfo bool
+	// CodeFunc returns the gRPC code for an error. DefaultCode is used if it's nil.
+	CodeFunc func(err error) codes.Code
+}
+
+// UnaryServerInterceptor returns a server interceptor that converts errors returned by unary handlers into statuses.
+func UnaryServerInterceptor(options Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			err = ToStatus(err, options).Err()
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that converts errors returned by stream handlers into
+// statuses.
+func StreamServerInterceptor(options Options) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			err = ToStatus(err, options).Err()
+		}
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that converts received statuses into eris errors.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return fromError(invoker(ctx, method, req, reply, cc, opts...), method)
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that converts statuses received on streams into eris errors.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, fromError(err, method)
+		}
+		return &clientStream{ClientStream: cs, method: method}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return fromError(s.ClientStream.SendMsg(m), s.method)
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return fromError(s.ClientStream.RecvMsg(m), s.method)
+}
+
+// fromError wraps a status error received from a method in a new eris error. io.EOF is returned as is since it
+// signals the end of a stream.
+func fromError(err error, method string) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return eris.Wrapf(FromStatus(st), "%v", method)
+}
+
+// ToStatus returns the gRPC status for an error.
+//
+// Errors that already implement `GRPCStatus() *status.Status` are returned as is. For all other errors, the code is
+// determined by the CodeFunc option and the message is the outermost error message (skipping wrap errors without a
+// message), so the context added by inner wrap errors isn't sent to the client. If the WithDebugInfo option is set, the
+// status contains the root error stack and the complete error message as errdetails.DebugInfo, and the fields of the
+// errors in the chain (see eris.Fields) as structpb.Struct. Field values that can't be converted to a structpb.Value
+// are sent as strings.
+func ToStatus(err error, options Options) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if se, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+		return se.GRPCStatus()
+	}
+
+	codeFunc := options.CodeFunc
+	if codeFunc == nil {
+		codeFunc = DefaultCode
+	}
+	upErr := eris.Unpack(err)
+	msg := upErr.ErrRoot.Msg
+	// wrap errors without a message (see eris.WithStack) are skipped
+	for i := len(upErr.ErrChain) - 1; i >= 0; i-- {
+		if upErr.ErrChain[i].Msg != "" {
+			msg = upErr.ErrChain[i].Msg
+			break
+		}
+	}
+	if msg == "" {
+		msg = err.Error()
+	}
+
+	st := status.New(codeFunc(err), msg)
+	if !options.WithDebugInfo {
+		return st
+	}
+	var stack []string
+	if rootMap, ok := eris.ToJSON(err, true)["root"].(map[string]interface{}); ok {
+		stack, _ = rootMap["stack"].([]string)
+	}
+	details := []protoadapt.MessageV1{&errdetails.DebugInfo{
+		StackEntries: stack,
+		Detail:       eris.ToString(err, false),
+	}}
+	if fields := eris.Fields(err); len(fields) > 0 {
+		details = append(details, fieldsStruct(fields))
+	}
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// fieldsStruct converts the fields of an error into a struct. Values that aren't supported by structpb.NewValue are
+// converted to strings.
+func fieldsStruct(fields map[string]interface{}) *structpb.Struct {
+	st := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(fields))}
+	for k, v := range fields {
+		value, err := structpb.NewValue(v)
+		if err != nil {
+			value = structpb.NewStringValue(fmt.Sprint(v))
+		}
+		st.Fields[k] = value
+	}
+	return st
+}
+
+// DefaultCode returns the code of the first error in the chain that implements `GRPCStatus() *status.Status`.
+// Context cancellations and deadlines are mapped to codes.Canceled and codes.DeadlineExceeded, and all other errors
+// are mapped to codes.Unknown.
+func DefaultCode(err error) codes.Code {
+	var se interface{ GRPCStatus() *status.Status }
+	switch {
+	case eris.As(err, &se):
+		return se.GRPCStatus().Code()
+	case eris.Is(err, context.Canceled):
+		return codes.Canceled
+	case eris.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Unknown
+	}
+}
+
+// FromStatus returns a RemoteError for a status received from a server.
+func FromStatus(st *status.Status) *RemoteError {
+	remoteErr := &RemoteError{status: st}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.DebugInfo:
+			if remoteErr.Detail == "" && remoteErr.Stack == nil {
+				remoteErr.Detail = detail.GetDetail()
+				remoteErr.Stack = detail.GetStackEntries()
+			}
+		case *structpb.Struct:
+			if remoteErr.Fields == nil {
+				remoteErr.Fields = detail.AsMap()
+			}
+		}
+	}
+	return remoteErr
+}
+
+// RemoteError is an error returned by a gRPC server, including the error trace sent by the server if there is one.
+type RemoteError struct {
+	Detail string                 // Complete error message on the server.
+	Stack  []string               // Root error stack on the server.
+	Fields map[string]interface{} // Fields of the errors on the server (see eris.Fields).
+	status *status.Status
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("rpc error: code = %v desc = %v", e.status.Code(), e.status.Message())
+}
+
+// Format prints the remote error trace with the '%+v' verb.
+func (e *RemoteError) Format(s fmt.State, verb rune) {
+	str := e.Error()
+	if verb == 'v' && s.Flag('+') && e.Detail != "" {
+		str += "\nremote error: " + e.Detail
+		for _, frame := range e.Stack {
+			str += "\n\t" + frame
+		}
+	}
+	_, _ = io.WriteString(s, str)
+}
+
+// GRPCStatus returns the status received from the server, so the error works with status.FromError and status.Code.
+func (e *RemoteError) GRPCStatus() *status.Status {
+	return e.status
+}
@@ -0,0 +1,232 @@
+package erisgrpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erisgrpc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer returns the same error from all methods.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func (s *healthServer) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	return s.err
+}
+
+func setupTestCase(t *testing.T, err error, options erisgrpc.Options, withClientInterceptors bool) healthpb.HealthClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(erisgrpc.UnaryServerInterceptor(options)),
+		grpc.StreamInterceptor(erisgrpc.StreamServerInterceptor(options)),
+	)
+	healthpb.RegisterHealthServer(srv, &healthServer{err: err})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	if withClientInterceptors {
+		dialOpts = append(dialOpts,
+			grpc.WithUnaryInterceptor(erisgrpc.UnaryClientInterceptor()),
+			grpc.WithStreamInterceptor(erisgrpc.StreamClientInterceptor()),
+		)
+	}
+	conn, dialErr := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if dialErr != nil {
+		t.Fatalf("failed to dial server: %v", dialErr)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestServerInterceptors(t *testing.T) {
+	tests := map[string]struct {
+		input   error
+		options erisgrpc.Options
+		code    codes.Code
+		msg     string
+	}{
+		"wrapped error": {
+			input: eris.Wrap(eris.New("failed to query table users"), "failed to get user"),
+			code:  codes.Unknown,
+			msg:   "failed to get user",
+		},
+		"wrapped status error": {
+			input: eris.Wrap(status.Error(codes.NotFound, "not found"), "failed to get user"),
+			code:  codes.NotFound,
+			msg:   "failed to get user",
+		},
+		"wrapped context error": {
+			input: eris.Wrap(context.DeadlineExceeded, "failed to get user"),
+			code:  codes.DeadlineExceeded,
+			msg:   "failed to get user",
+		},
+		"error with stack": {
+			input: eris.WithStack(eris.Wrap(eris.New("failed to query table users"), "failed to get user")),
+			code:  codes.Unknown,
+			msg:   "failed to get user",
+		},
+		"root error with stack": {
+			input: eris.WithStack(eris.New("failed to get user")),
+			code:  codes.Unknown,
+			msg:   "failed to get user",
+		},
+		"custom code": {
+			input:   eris.New("failed to get user"),
+			options: erisgrpc.Options{CodeFunc: func(error) codes.Code { return codes.Internal }},
+			code:    codes.Internal,
+			msg:     "failed to get user",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			client := setupTestCase(t, tt.input, tt.options, false)
+
+			_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+			if st := status.Convert(err); st.Code() != tt.code || st.Message() != tt.msg || len(st.Details()) != 0 {
+				t.Errorf("unary: expected status { %v %v } got { %v %v %v }", tt.code, tt.msg, st.Code(), st.Message(), st.Details())
+			}
+
+			stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatalf("failed to open stream: %v", err)
+			}
+			_, err = stream.Recv()
+			if st := status.Convert(err); st.Code() != tt.code || st.Message() != tt.msg {
+				t.Errorf("stream: expected status { %v %v } got { %v %v }", tt.code, tt.msg, st.Code(), st.Message())
+			}
+		})
+	}
+}
+
+func TestServerInterceptorsWithDebugInfo(t *testing.T) {
+	input := eris.Wrap(eris.New("failed to query table users"), "failed to get user")
+	client := setupTestCase(t, input, erisgrpc.Options{WithDebugInfo: true}, false)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	st := status.Convert(err)
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected 1 status detail, got %v", len(st.Details()))
+	}
+	debugInfo, ok := st.Details()[0].(*errdetails.DebugInfo)
+	if !ok {
+		t.Fatalf("expected debug info, got %T", st.Details()[0])
+	}
+	if debugInfo.GetDetail() != "failed to get user: failed to query table users" {
+		t.Errorf("expected debug detail { %v } got { %v }", input.Error(), debugInfo.GetDetail())
+	}
+	if !strings.Contains(strings.Join(debugInfo.GetStackEntries(), "\n"), "erisgrpc_test.TestServerInterceptorsWithDebugInfo") {
+		t.Errorf("expected the stack to contain the test function { %v }", debugInfo.GetStackEntries())
+	}
+}
+
+func TestClientInterceptors(t *testing.T) {
+	input := eris.Wrap(eris.New("failed to query table users"), "failed to get user")
+	client := setupTestCase(t, input, erisgrpc.Options{WithDebugInfo: true}, true)
+
+	_, unaryErr := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	_, streamErr := stream.Recv()
+
+	for method, err := range map[string]error{
+		healthpb.Health_Check_FullMethodName: unaryErr,
+		healthpb.Health_Watch_FullMethodName: streamErr,
+	} {
+		want := method + ": rpc error: code = Unknown desc = failed to get user"
+		if err == nil || err.Error() != want {
+			t.Fatalf("expected error { %v } got { %v }", want, err)
+		}
+		if code := status.Code(err); code != codes.Unknown {
+			t.Errorf("expected code { %v } got { %v }", codes.Unknown, code)
+		}
+
+		var remoteErr *erisgrpc.RemoteError
+		if !eris.As(err, &remoteErr) {
+			t.Fatalf("expected a remote error in the chain { %v }", err)
+		}
+		if remoteErr.Detail != "failed to get user: failed to query table users" || len(remoteErr.Stack) == 0 {
+			t.Errorf("expected the remote trace, got { %v %v }", remoteErr.Detail, remoteErr.Stack)
+		}
+		if trace := fmt.Sprintf("%+v", err); !strings.Contains(trace, "remote error: failed to get user") ||
+			!strings.Contains(trace, "erisgrpc_test.TestClientInterceptors") {
+			t.Errorf("expected the local and remote trace { %v }", trace)
+		}
+	}
+}
+
+type ctxKey string
+
+func TestClientInterceptorsWithFields(t *testing.T) {
+	eris.RegisterContextExtractor("request_id", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(ctxKey("request_id")).(string)
+		return v, ok
+	})
+	eris.RegisterContextExtractor("attempt", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(ctxKey("attempt")).(int)
+		return v, ok
+	})
+	eris.RegisterContextExtractor("started", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(ctxKey("started")).(time.Duration)
+		return v, ok
+	})
+	defer eris.RegisterContextExtractor("request_id", nil)
+	defer eris.RegisterContextExtractor("attempt", nil)
+	defer eris.RegisterContextExtractor("started", nil)
+
+	tests := map[string]struct {
+		options erisgrpc.Options
+		fields  map[string]interface{} // expected remote fields
+	}{
+		"with debug info": {
+			options: erisgrpc.Options{WithDebugInfo: true},
+			fields:  map[string]interface{}{"request_id": "req-7", "attempt": float64(2), "started": "1.5s"},
+		},
+		"without debug info": {},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), ctxKey("request_id"), "req-7")
+			ctx = context.WithValue(ctx, ctxKey("attempt"), 2)
+			ctx = context.WithValue(ctx, ctxKey("started"), 1500*time.Millisecond)
+			input := eris.WrapCtx(ctx, eris.New("failed to query table users"), "failed to get user")
+			client := setupTestCase(t, input, tt.options, true)
+
+			_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+			var remoteErr *erisgrpc.RemoteError
+			if !eris.As(err, &remoteErr) {
+				t.Fatalf("expected a remote error in the chain { %v }", err)
+			}
+			if !reflect.DeepEqual(remoteErr.Fields, tt.fields) {
+				t.Errorf("expected remote fields { %v } got { %v }", tt.fields, remoteErr.Fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/rotisserie/eris/erisgrpc
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/rotisserie/eris v0.5.4
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7
+	google.golang.org/grpc v1.75.1
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+)
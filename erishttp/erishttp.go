@@ -0,0 +1,132 @@
+// Package erishttp writes eris errors as HTTP responses and recovers panics in HTTP handlers.
+package erishttp
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/rotisserie/eris"
+)
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Options defines how errors are written to HTTP responses. Any nil hook is replaced with its default.
+type Options struct {
+	// StatusFunc returns the HTTP status for an error. By default, it uses the status of the first error in the
+	// chain that implements `StatusCode() int` and falls back to 500 (Internal Server Error).
+	StatusFunc func(err error) int
+	// ProblemFunc returns the response body for an error. By default, the body only contains the status, its title,
+	// and the public error message, so no internal error messages are sent to the client.
+	ProblemFunc func(r *http.Request, err error, status int) Problem
+	// LogFunc logs an error on the server. By default, it logs the error with its stack trace via the log package.
+	LogFunc func(r *http.Request, err error)
+}
+
+// ErrorWriter writes errors as RFC 7807 problem responses.
+type ErrorWriter struct {
+	options Options
+}
+
+// NewErrorWriter returns an error writer with custom options.
+func NewErrorWriter(options Options) *ErrorWriter {
+	if options.StatusFunc == nil {
+		options.StatusFunc = DefaultStatus
+	}
+	if options.ProblemFunc == nil {
+		options.ProblemFunc = DefaultProblem
+	}
+	if options.LogFunc == nil {
+		options.LogFunc = DefaultLog
+	}
+	return &ErrorWriter{options: options}
+}
+
+var defaultWriter = NewErrorWriter(Options{})
+
+// WriteError logs an error and writes it to the response with the default options.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	defaultWriter.WriteError(w, r, err)
+}
+
+// Middleware recovers panics in the next handler and writes them to the response with the default options.
+func Middleware(next http.Handler) http.Handler {
+	return defaultWriter.Middleware(next)
+}
+
+// WriteError logs an error and writes it to the response as an `application/problem+json` body. Nothing is written
+// if err is nil.
+func (ew *ErrorWriter) WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	ew.options.LogFunc(r, err)
+
+	status := ew.options.StatusFunc(err)
+	problem := ew.options.ProblemFunc(r, err, status)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// Middleware recovers panics in the next handler, converts them into eris errors, and writes them to the response.
+//
+// Panics with http.ErrAbortHandler are passed on since they're used to abort a response on purpose.
+func (ew *ErrorWriter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			var err error
+			if e, ok := v.(error); ok {
+				err = eris.Wrap(e, "recovered from panic")
+			} else {
+				err = eris.Errorf("recovered from panic: %v", v)
+			}
+			ew.WriteError(w, r, err)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DefaultStatus returns the status of the first error in the chain that implements `StatusCode() int` or 500
+// (Internal Server Error) if there isn't one.
+func DefaultStatus(err error) int {
+	var target interface{ StatusCode() int }
+	if eris.As(err, &target) {
+		if status := target.StatusCode(); status >= 400 && status <= 599 {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// DefaultProblem returns a problem with the status, its title, the public error message (see eris.PublicMessage),
+// and the request path.
+func DefaultProblem(r *http.Request, err error, status int) Problem {
+	return Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   eris.PublicMessage(err),
+		Instance: r.URL.Path,
+	}
+}
+
+// DefaultLog logs the request and the error with its stack trace.
+func DefaultLog(r *http.Request, err error) {
+	log.Printf("%v %v: %+v", r.Method, r.URL.Path, err)
+}
@@ -0,0 +1,163 @@
+package erishttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erishttp"
+)
+
+type statusError struct {
+	status int
+}
+
+func (e statusError) Error() string   { return http.StatusText(e.status) }
+func (e statusError) StatusCode() int { return e.status }
+
+func TestWriteError(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		status int
+		detail string
+	}{
+		"eris error": {
+			input:  eris.Wrap(eris.New("failed to query table users"), "failed to get user"),
+			status: http.StatusInternalServerError,
+			detail: eris.DefaultPublicMessage,
+		},
+		"eris error with public message": {
+			input:  eris.Wrap(eris.WithPublicMessage(eris.New("failed to query table users"), "user not available"), "failed to get user"),
+			status: http.StatusInternalServerError,
+			detail: "user not available",
+		},
+		"external error with status": {
+			input:  eris.Wrap(statusError{status: http.StatusNotFound}, "failed to query table users"),
+			status: http.StatusNotFound,
+			detail: eris.DefaultPublicMessage,
+		},
+		"external error with invalid status": {
+			input:  eris.Wrap(statusError{status: http.StatusOK}, "failed to query table users"),
+			status: http.StatusInternalServerError,
+			detail: eris.DefaultPublicMessage,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var logged error
+			ew := erishttp.NewErrorWriter(erishttp.Options{
+				LogFunc: func(r *http.Request, err error) { logged = err },
+			})
+			rec := httptest.NewRecorder()
+			ew.WriteError(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil), tt.input)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status { %v } got { %v }", tt.status, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("expected content type { application/problem+json } got { %v }", ct)
+			}
+			if logged != tt.input {
+				t.Errorf("expected the error to be logged { %v } got { %v }", tt.input, logged)
+			}
+			if strings.Contains(rec.Body.String(), "failed") {
+				t.Errorf("expected no internal messages in the response { %v }", rec.Body.String())
+			}
+
+			var problem erishttp.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal problem: %v", err)
+			}
+			want := erishttp.Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.status),
+				Status:   tt.status,
+				Detail:   tt.detail,
+				Instance: "/users/1",
+			}
+			if problem != want {
+				t.Errorf("expected problem { %+v } got { %+v }", want, problem)
+			}
+		})
+	}
+}
+
+func TestWriteErrorCustomProblem(t *testing.T) {
+	ew := erishttp.NewErrorWriter(erishttp.Options{
+		StatusFunc: func(err error) int { return http.StatusTeapot },
+		ProblemFunc: func(r *http.Request, err error, status int) erishttp.Problem {
+			return erishttp.Problem{Title: "custom", Status: status}
+		},
+		LogFunc: func(r *http.Request, err error) {},
+	})
+	rec := httptest.NewRecorder()
+	ew.WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), eris.New("root error"))
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("expected status { %v } got { %v }", http.StatusTeapot, rec.Code)
+	}
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"title":"custom","status":418}` {
+		t.Errorf("expected custom problem, got { %v }", body)
+	}
+
+	rec = httptest.NewRecorder()
+	ew.WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected nothing to be written for nil errors, got { %v }", rec.Body.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := map[string]struct {
+		value  interface{}
+		output string
+	}{
+		"panic with value": {
+			value:  "something went wrong",
+			output: "recovered from panic: something went wrong",
+		},
+		"panic with error": {
+			value:  errors.New("external error"),
+			output: "recovered from panic: external error",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var logged error
+			ew := erishttp.NewErrorWriter(erishttp.Options{
+				LogFunc: func(r *http.Request, err error) { logged = err },
+			})
+			handler := ew.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic(tt.value)
+			}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("expected status { %v } got { %v }", http.StatusInternalServerError, rec.Code)
+			}
+			if logged == nil || logged.Error() != tt.output {
+				t.Fatalf("expected logged error { %v } got { %v }", tt.output, logged)
+			}
+			if trace := eris.ToString(logged, true); !strings.Contains(trace, "erishttp_test.TestMiddleware") {
+				t.Errorf("expected the trace to contain the panicking handler { %v }", trace)
+			}
+		})
+	}
+}
+
+func TestMiddlewareAbortHandler(t *testing.T) {
+	handler := erishttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected panic { %v } got { %v }", http.ErrAbortHandler, v)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
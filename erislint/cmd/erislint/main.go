@@ -0,0 +1,15 @@
+// Command erislint reports common mistakes when using eris (see the erislint package for the full list).
+//
+// Usage:
+//
+//   erislint [flags] [packages]
+package main
+
+import (
+	"github.com/rotisserie/eris/erislint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(erislint.Analyzer)
+}
@@ -0,0 +1,368 @@
+// Package erislint defines an analyzer that reports common mistakes when using eris.
+package erislint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const erisPath = "github.com/rotisserie/eris"
+
+const doc = `report common mistakes when using eris
+
+The erislint analyzer reports:
+
+  - errors from other packages that are returned without being wrapped, so they don't have any context or stack
+  - eris.Wrap calls with a message that's identical to the message of the wrapped error
+  - errors.Is calls with an eris error as the target (use eris.Is instead)
+  - eris errors created inside loops, which capture a new stack on each iteration
+  - eris errors compared with == or !=, which fails after the error was wrapped (use eris.Is instead)`
+
+// Analyzer reports common mistakes when using eris.
+var Analyzer = &analysis.Analyzer{
+	Name:      "erislint",
+	Doc:       doc,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(erisErrorFact)},
+}
+
+// erisErrorFact marks a package-level variable that's initialized with an eris error (e.g. a sentinel error created
+// with eris.New).
+type erisErrorFact struct {
+	Msg string // constant message of the error if there is one
+}
+
+func (*erisErrorFact) AFact() {}
+
+func (f *erisErrorFact) String() string {
+	return fmt.Sprintf("erisError(%q)", f.Msg)
+}
+
+// constructors are the eris functions that create new root errors.
+var constructors = map[string]bool{
+	"New":       true,
+	"Errorf":    true,
+	"NewPublic": true,
+	"NewCtx":    true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	exportFacts(pass)
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.ReturnStmt)(nil),
+		(*ast.CallExpr)(nil),
+		(*ast.BinaryExpr)(nil),
+		(*ast.SwitchStmt)(nil),
+	}
+	inspect.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.ReturnStmt:
+			checkReturn(pass, n, stack)
+		case *ast.CallExpr:
+			checkWrap(pass, n)
+			checkIs(pass, n)
+			checkLoop(pass, n, stack)
+		case *ast.BinaryExpr:
+			checkCompare(pass, n)
+		case *ast.SwitchStmt:
+			checkSwitch(pass, n)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// exportFacts marks the package-level variables that are initialized with eris errors.
+func exportFacts(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Names) != len(spec.Values) {
+					continue
+				}
+				for i, name := range spec.Names {
+					call, ok := unparen(spec.Values[i]).(*ast.CallExpr)
+					if !ok || !isErisFunc(pass, call, constructors) {
+						continue
+					}
+					obj := pass.TypesInfo.Defs[name]
+					if obj == nil {
+						continue
+					}
+					fact := &erisErrorFact{}
+					// the message is the last argument except for formatted messages
+					fn := typeutil.Callee(pass.TypesInfo, call)
+					if msg, ok := constString(pass, call.Args, len(call.Args)-1); ok && (fn.Name() != "Errorf" || len(call.Args) == 1) {
+						fact.Msg = msg
+					}
+					pass.ExportObjectFact(obj, fact)
+				}
+			}
+		}
+	}
+}
+
+// checkReturn reports errors from other packages that are returned without being wrapped.
+func checkReturn(pass *analysis.Pass, ret *ast.ReturnStmt, stack []ast.Node) {
+	body, sig := enclosingFunc(pass, stack)
+	if sig == nil || sig.Results().Len() != len(ret.Results) {
+		return
+	}
+	for i, result := range ret.Results {
+		if !isErrorType(sig.Results().At(i).Type()) {
+			continue
+		}
+		call := sourceCall(pass, unparen(result), body, ret.Pos())
+		if call == nil {
+			continue
+		}
+		callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || callee.Pkg() == nil || callee.Pkg() == pass.Pkg || isIgnoredPkg(callee.Pkg().Path()) {
+			continue
+		}
+		pass.Reportf(result.Pos(), "error returned from %v.%v is not wrapped, use eris.Wrap to add context and a stack trace",
+			callee.Pkg().Name(), callee.Name())
+	}
+}
+
+// checkWrap reports eris.Wrap calls with a message that's identical to the message of the wrapped error.
+func checkWrap(pass *analysis.Pass, call *ast.CallExpr) {
+	if !isErisFunc(pass, call, map[string]bool{"Wrap": true}) || len(call.Args) != 2 {
+		return
+	}
+	err, msg := call.Args[0], unparen(call.Args[1])
+
+	// eris.Wrap(err, err.Error())
+	if errCall, ok := msg.(*ast.CallExpr); ok && len(errCall.Args) == 0 {
+		if sel, ok := errCall.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Error" &&
+			types.ExprString(unparen(sel.X)) == types.ExprString(unparen(err)) {
+			pass.Reportf(call.Pos(), "eris.Wrap message is identical to the message of the wrapped error")
+			return
+		}
+	}
+
+	// eris.Wrap(ErrNotFound, "not found") with ErrNotFound = eris.New("not found")
+	if fact := erisErrorOf(pass, err); fact != nil && fact.Msg != "" {
+		if s, ok := constString(pass, call.Args, 1); ok && s == fact.Msg {
+			pass.Reportf(call.Pos(), "eris.Wrap message is identical to the message of the wrapped error")
+		}
+	}
+}
+
+// checkIs reports errors.Is calls with an eris error as the target.
+func checkIs(pass *analysis.Pass, call *ast.CallExpr) {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || callee.Pkg() == nil || callee.Pkg().Path() != "errors" || callee.Name() != "Is" || len(call.Args) != 2 {
+		return
+	}
+	if erisErrorOf(pass, call.Args[1]) != nil {
+		pass.Reportf(call.Pos(), "errors.Is with eris error %v as the target, use eris.Is instead", types.ExprString(call.Args[1]))
+	}
+}
+
+// checkLoop reports eris errors that are created inside loops. Errors that are returned right away are ignored since
+// they're only created once.
+func checkLoop(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) {
+	if !isErisFunc(pass, call, constructors) {
+		return
+	}
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.ReturnStmt, *ast.FuncLit, *ast.FuncDecl:
+			return
+		case *ast.ForStmt:
+			if stack[i+1] == n.Body {
+				pass.Reportf(call.Pos(), "eris error created inside a loop captures a new stack on each iteration, create it outside the loop")
+				return
+			}
+		case *ast.RangeStmt:
+			if stack[i+1] == n.Body {
+				pass.Reportf(call.Pos(), "eris error created inside a loop captures a new stack on each iteration, create it outside the loop")
+				return
+			}
+		}
+	}
+}
+
+// checkCompare reports eris errors that are compared with == or !=.
+func checkCompare(pass *analysis.Pass, expr *ast.BinaryExpr) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+	for _, operand := range []ast.Expr{expr.X, expr.Y} {
+		if erisErrorOf(pass, operand) != nil {
+			pass.Reportf(expr.Pos(), "eris error %v compared with %v, use eris.Is instead since wrapped errors are copies", types.ExprString(operand), expr.Op)
+			return
+		}
+	}
+}
+
+// checkSwitch reports eris errors that are compared in the cases of a switch statement.
+func checkSwitch(pass *analysis.Pass, stmt *ast.SwitchStmt) {
+	if stmt.Tag == nil || !isErrorType(pass.TypesInfo.TypeOf(stmt.Tag)) {
+		return
+	}
+	for _, clause := range stmt.Body.List {
+		for _, expr := range clause.(*ast.CaseClause).List {
+			if erisErrorOf(pass, expr) != nil {
+				pass.Reportf(expr.Pos(), "eris error %v compared with ==, use eris.Is instead since wrapped errors are copies", types.ExprString(expr))
+			}
+		}
+	}
+}
+
+// enclosingFunc returns the body and signature of the innermost function in the stack.
+func enclosingFunc(pass *analysis.Pass, stack []ast.Node) (*ast.BlockStmt, *types.Signature) {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.FuncDecl:
+			if obj, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok {
+				return n.Body, obj.Type().(*types.Signature)
+			}
+			return nil, nil
+		case *ast.FuncLit:
+			sig, _ := pass.TypesInfo.TypeOf(n).(*types.Signature)
+			return n.Body, sig
+		}
+	}
+	return nil, nil
+}
+
+// sourceCall returns the call that produced a returned value: either the returned expression itself or the call of
+// the last assignment to the returned variable before the return statement.
+func sourceCall(pass *analysis.Pass, expr ast.Expr, body *ast.BlockStmt, pos token.Pos) *ast.CallExpr {
+	if call, ok := expr.(*ast.CallExpr); ok {
+		return call
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok || body == nil {
+		return nil
+	}
+	obj := pass.TypesInfo.ObjectOf(ident)
+	if obj == nil {
+		return nil
+	}
+
+	var last *ast.CallExpr
+	var lastPos token.Pos
+	assign := func(lhs []*ast.Ident, rhs []ast.Expr) {
+		for i, name := range lhs {
+			if name == nil || pass.TypesInfo.ObjectOf(name) != obj || name.Pos() > pos || name.Pos() < lastPos {
+				continue
+			}
+			var value ast.Expr
+			if len(lhs) == len(rhs) {
+				value = rhs[i]
+			} else if len(rhs) == 1 {
+				value = rhs[0]
+			}
+			last, _ = unparen(value).(*ast.CallExpr)
+			lastPos = name.Pos()
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			lhs := make([]*ast.Ident, len(n.Lhs))
+			for i, e := range n.Lhs {
+				lhs[i], _ = e.(*ast.Ident)
+			}
+			assign(lhs, n.Rhs)
+		case *ast.ValueSpec:
+			assign(n.Names, n.Values)
+		}
+		return true
+	})
+	return last
+}
+
+// erisErrorOf returns the fact of a package-level variable that's initialized with an eris error or nil if the
+// expression doesn't refer to one.
+func erisErrorOf(pass *analysis.Pass, expr ast.Expr) *erisErrorFact {
+	var obj types.Object
+	switch e := unparen(expr).(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[e]
+	case *ast.SelectorExpr:
+		obj = pass.TypesInfo.Uses[e.Sel]
+	}
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+	var fact erisErrorFact
+	if !pass.ImportObjectFact(v, &fact) {
+		return nil
+	}
+	return &fact
+}
+
+// isErisFunc reports whether a call calls one of the given functions of the eris package.
+func isErisFunc(pass *analysis.Pass, call *ast.CallExpr, names map[string]bool) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == erisPath && names[fn.Name()]
+}
+
+// ignoredPkgs are error handling packages that create new errors (e.g. errors.New and fmt.Errorf) or already add
+// context and a stack.
+var ignoredPkgs = map[string]bool{
+	"errors":                        true,
+	"fmt":                           true,
+	"github.com/pkg/errors":         true,
+	"github.com/go-errors/errors":   true,
+	"github.com/cockroachdb/errors": true,
+	"golang.org/x/xerrors":          true,
+}
+
+// isIgnoredPkg reports whether errors returned by a package don't have to be wrapped.
+func isIgnoredPkg(path string) bool {
+	return ignoredPkgs[path] || path == erisPath || strings.HasPrefix(path, erisPath+"/")
+}
+
+// isErrorType reports whether a type is the error interface.
+func isErrorType(t types.Type) bool {
+	return t != nil && types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// constString returns the value of a constant string argument.
+func constString(pass *analysis.Pass, args []ast.Expr, i int) (string, bool) {
+	if i < 0 || i >= len(args) {
+		return "", false
+	}
+	tv, ok := pass.TypesInfo.Types[args[i]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// unparen returns an expression without enclosing parentheses.
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		p, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.X
+	}
+}
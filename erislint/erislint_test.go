@@ -0,0 +1,12 @@
+package erislint_test
+
+import (
+	"testing"
+
+	"github.com/rotisserie/eris/erislint"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), erislint.Analyzer, "a", "b")
+}
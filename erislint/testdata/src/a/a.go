@@ -0,0 +1,132 @@
+package a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"b"
+
+	"github.com/rotisserie/eris"
+)
+
+var (
+	errLocal   = eris.New("local error")                            // want errLocal:`erisError\("local error"\)`
+	errCtx     = eris.NewCtx(context.Background(), "context error") // want errCtx:`erisError\("context error"\)`
+	errFormat  = eris.Errorf("format error %v", 1)                  // want errFormat:`erisError\(""\)`
+	errStd     = errors.New("standard error")
+	errDerived = fmt.Errorf("derived: %w", errLocal)
+)
+
+// unwrapped errors across package boundaries
+
+func readConfig() ([]byte, error) {
+	data, err := os.ReadFile("config.json")
+	if err != nil {
+		return nil, err // want `error returned from os.ReadFile is not wrapped, use eris.Wrap to add context and a stack trace`
+	}
+	return data, nil
+}
+
+func load() error {
+	return b.Load() // want `error returned from b.Load is not wrapped`
+}
+
+func open() error {
+	_, err := b.Open("file")
+	if err != nil {
+		return eris.Wrap(err, "failed to open file")
+	}
+	err = local()
+	return err
+}
+
+func local() error {
+	if err := validate(); err != nil {
+		return err
+	}
+	return fmt.Errorf("local: %w", errStd)
+}
+
+func validate() error {
+	return errLocal
+}
+
+func closure() func() error {
+	return func() error {
+		_, err := b.Open("file")
+		return err // want `error returned from b.Open is not wrapped`
+	}
+}
+
+// wrap messages identical to the wrapped error
+
+func wrapSame(err error) error {
+	if err == nil {
+		return eris.Wrap(errLocal, "local error") // want `eris.Wrap message is identical to the message of the wrapped error`
+	}
+	if err == errStd {
+		return eris.Wrap(b.ErrNotFound, "not found") // want `eris.Wrap message is identical to the message of the wrapped error`
+	}
+	if err == errCtx { // want `eris error errCtx compared with ==`
+		return eris.Wrap(errCtx, "context error") // want `eris.Wrap message is identical to the message of the wrapped error`
+	}
+	return eris.Wrap(err, err.Error()) // want `eris.Wrap message is identical to the message of the wrapped error`
+}
+
+func wrapDifferent(err error) error {
+	if err == nil {
+		return eris.Wrap(errFormat, "format error 1")
+	}
+	return eris.Wrap(errLocal, "additional context")
+}
+
+// errors.Is with eris errors
+
+func is(err error) bool {
+	if errors.Is(err, errStd) || eris.Is(err, errLocal) {
+		return true
+	}
+	return errors.Is(err, b.ErrNotFound) // want `errors.Is with eris error b.ErrNotFound as the target, use eris.Is instead`
+}
+
+// eris errors created inside loops
+
+func loop(names []string) []error {
+	var errs []error
+	for i := 0; i < len(names); i++ {
+		errs = append(errs, eris.New("invalid name")) // want `eris error created inside a loop captures a new stack on each iteration`
+	}
+	for _, name := range names {
+		if name == "" {
+			return []error{eris.Errorf("empty name")}
+		}
+		check := func() error {
+			return eris.Errorf("invalid name %v", name)
+		}
+		errs = append(errs, check(), eris.Errorf("invalid name %v", name)) // want `eris error created inside a loop`
+	}
+	return errs
+}
+
+// eris errors compared with ==
+
+func compare(err error) string {
+	if err == errLocal { // want `eris error errLocal compared with ==, use eris.Is instead since wrapped errors are copies`
+		return "local"
+	}
+	if b.ErrNotFound != err { // want `eris error b.ErrNotFound compared with !=`
+		return "found"
+	}
+	if err == errStd || err == errDerived || err == nil {
+		return "standard"
+	}
+	switch err {
+	case errLocal: // want `eris error errLocal compared with ==`
+		return "local"
+	case errStd:
+		return "standard"
+	}
+	return ""
+}
@@ -0,0 +1,13 @@
+package b
+
+import "github.com/rotisserie/eris"
+
+var ErrNotFound = eris.New("not found") // want ErrNotFound:`erisError\("not found"\)`
+
+func Load() error {
+	return ErrNotFound
+}
+
+func Open(name string) (int, error) {
+	return 0, eris.Wrap(ErrNotFound, "failed to open "+name)
+}
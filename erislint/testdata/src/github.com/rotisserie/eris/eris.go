@@ -0,0 +1,11 @@
+// Package eris is a stub of the eris package for testing the analyzer.
+package eris
+
+import "context"
+
+func New(msg string) error                                      { return nil }
+func NewCtx(ctx context.Context, msg string) error              { return nil }
+func Errorf(format string, args ...interface{}) error           { return nil }
+func Wrap(err error, msg string) error                          { return err }
+func Wrapf(err error, format string, args ...interface{}) error { return err }
+func Is(err, target error) bool                                 { return err == target }
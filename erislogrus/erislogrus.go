@@ -0,0 +1,79 @@
+// Package erislogrus provides a logrus hook that expands eris errors into structured JSON documents.
+package erislogrus
+
+import (
+	"github.com/rotisserie/eris"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook replaces eris errors in log entries with the JSON document returned by eris.ToCustomJSON.
+//
+// Errors that don't contain any eris errors are logged as is.
+type Hook struct {
+	ErrorKey        string          // Key of the error field in log entries (logrus.ErrorKey by default).
+	Format          eris.JSONFormat // Format of the JSON error document.
+	WithRoot        bool            // Flag that adds the root error message as the top-level "error.root" field.
+	WithFingerprint bool            // Flag that adds the fingerprint of the error (see eris.Fingerprint) as the top-level "error.fingerprint" field.
+	WithCode        bool            // Flag that adds the code of the error as the top-level "error.code" field.
+	// CodeFunc returns the code of an error and reports whether the error has one. DefaultCode is used if it's nil.
+	CodeFunc func(err error) (interface{}, bool)
+}
+
+// NewHook returns a hook that formats errors in the error field of log entries with a given JSON format.
+func NewHook(format eris.JSONFormat) *Hook {
+	return &Hook{
+		ErrorKey: logrus.ErrorKey,
+		Format:   format,
+	}
+}
+
+// Levels returns the log levels the hook is fired for, which are all levels.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire expands the eris error in a log entry.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	key := h.ErrorKey
+	if key == "" {
+		key = logrus.ErrorKey
+	}
+	err, ok := entry.Data[key].(error)
+	if !ok || err == nil {
+		return nil
+	}
+
+	upErr := eris.Unpack(err)
+	if upErr.ErrRoot.Msg == "" && len(upErr.ErrRoot.Stack) == 0 {
+		// not an eris error
+		return nil
+	}
+
+	entry.Data[key] = eris.ToCustomJSON(err, h.Format)
+	if h.WithRoot {
+		entry.Data[key+".root"] = upErr.ErrRoot.Msg
+	}
+	if h.WithFingerprint {
+		entry.Data[key+".fingerprint"] = eris.Fingerprint(upErr)
+	}
+	if h.WithCode {
+		codeFunc := h.CodeFunc
+		if codeFunc == nil {
+			codeFunc = DefaultCode
+		}
+		if code, ok := codeFunc(err); ok {
+			entry.Data[key+".code"] = code
+		}
+	}
+	return nil
+}
+
+// DefaultCode returns the code of the first error in the chain that implements `StatusCode() int` (e.g. an HTTP status
+// code) and reports whether there is one.
+func DefaultCode(err error) (interface{}, bool) {
+	var target interface{ StatusCode() int }
+	if eris.As(err, &target) {
+		return target.StatusCode(), true
+	}
+	return nil, false
+}
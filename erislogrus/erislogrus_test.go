@@ -0,0 +1,151 @@
+package erislogrus_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erislogrus"
+	"github.com/sirupsen/logrus"
+)
+
+func logError(t *testing.T, hook *erislogrus.Hook, err error) map[string]interface{} {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(hook)
+	logger.WithError(err).Error("method completed with error")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to unmarshal log entry: %v", err)
+	}
+	return entry
+}
+
+func TestHook(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		format eris.JSONFormat
+	}{
+		"wrapped error": {
+			input:  eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{}),
+		},
+		"wrapped error with trace": {
+			input:  eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, InvertTrace: true}),
+		},
+		"wrapped external error": {
+			input:  eris.Wrap(errors.New("external error"), "additional context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{WithExternal: true}),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			entry := logError(t, erislogrus.NewHook(tt.format), tt.input)
+
+			var want interface{}
+			result, _ := json.Marshal(eris.ToCustomJSON(tt.input, tt.format))
+			_ = json.Unmarshal(result, &want)
+			if !reflect.DeepEqual(entry["error"], want) {
+				t.Errorf("expected { %v } got { %v }", want, entry["error"])
+			}
+			if _, exists := entry["error.root"]; exists {
+				t.Errorf("expected no root field by default { %v }", entry)
+			}
+		})
+	}
+}
+
+func TestHookExternalError(t *testing.T) {
+	entry := logError(t, erislogrus.NewHook(eris.NewDefaultJSONFormat(eris.FormatOptions{})), errors.New("external error"))
+	if entry["error"] != "external error" {
+		t.Errorf("expected external errors to be logged as is, got { %v }", entry["error"])
+	}
+}
+
+func TestHookTopLevelFields(t *testing.T) {
+	hook := erislogrus.NewHook(eris.NewDefaultJSONFormat(eris.FormatOptions{}))
+	hook.WithRoot = true
+	hook.WithFingerprint = true
+
+	newErr := func() error {
+		return eris.New("root error")
+	}
+	first := logError(t, hook, eris.Wrap(newErr(), "additional context"))
+	second := logError(t, hook, eris.Wrap(newErr(), "other context"))
+	other := logError(t, hook, eris.Wrap(eris.New("other error"), "additional context"))
+
+	if first["error.root"] != "root error" {
+		t.Errorf("expected root field { %v } got { %v }", "root error", first["error.root"])
+	}
+	fingerprint, _ := first["error.fingerprint"].(string)
+	if fingerprint == "" || strings.Trim(fingerprint, "0123456789abcdef") != "" {
+		t.Fatalf("expected a hex fingerprint, got { %v }", first["error.fingerprint"])
+	}
+	if second["error.fingerprint"] != fingerprint {
+		t.Errorf("expected the same fingerprint for the same root error { %v } got { %v }", fingerprint, second["error.fingerprint"])
+	}
+	if other["error.fingerprint"] == fingerprint {
+		t.Errorf("expected a different fingerprint for a different root error { %v }", fingerprint)
+	}
+}
+
+// statusError is an external error with an HTTP status code.
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return "status error"
+}
+
+func (e *statusError) StatusCode() int {
+	return e.status
+}
+
+func TestHookCode(t *testing.T) {
+	tests := map[string]struct {
+		input    error
+		codeFunc func(err error) (interface{}, bool)
+		code     interface{} // expected code (nil for no code field)
+	}{
+		"error with status code": {
+			input: eris.Wrap(&statusError{status: 404}, "additional context"),
+			code:  float64(404),
+		},
+		"error without code": {
+			input: eris.Wrap(eris.New("root error"), "additional context"),
+		},
+		"custom code": {
+			input:    eris.New("root error"),
+			codeFunc: func(error) (interface{}, bool) { return "E_ROOT", true },
+			code:     "E_ROOT",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			hook := erislogrus.NewHook(eris.NewDefaultJSONFormat(eris.FormatOptions{}))
+			hook.WithCode = true
+			hook.CodeFunc = tt.codeFunc
+			entry := logError(t, hook, tt.input)
+			if code, exists := entry["error.code"]; code != tt.code || exists != (tt.code != nil) {
+				t.Errorf("expected code field { %v } got { %v }", tt.code, entry["error.code"])
+			}
+		})
+	}
+
+	// the code is only added if it's enabled
+	entry := logError(t, erislogrus.NewHook(eris.NewDefaultJSONFormat(eris.FormatOptions{})), eris.Wrap(&statusError{status: 404}, "additional context"))
+	if _, exists := entry["error.code"]; exists {
+		t.Errorf("expected no code field by default { %v }", entry)
+	}
+}
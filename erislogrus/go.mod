@@ -0,0 +1,12 @@
+module github.com/rotisserie/eris/erislogrus
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/rotisserie/eris v0.5.4
+	github.com/sirupsen/logrus v1.10.2
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,111 @@
+// Package erisotel records eris errors on OpenTelemetry spans.
+package erisotel
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rotisserie/eris"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options defines how errors are recorded on spans.
+type Options struct {
+	// CodeFunc returns the code of an error and reports whether the error has one. DefaultCode is used if it's nil.
+	CodeFunc func(err error) (interface{}, bool)
+}
+
+// RecordError adds an exception event for an error to a span and sets the span status to error. It's the same as
+// RecordErrorWithOptions with the default options.
+func RecordError(span trace.Span, err error, options ...trace.EventOption) {
+	RecordErrorWithOptions(span, err, Options{}, options...)
+}
+
+// RecordErrorWithOptions adds an exception event for an error to a span and sets the span status to error.
+//
+// The exception type is the type of the root cause of the error. eris errors don't have distinct types, so the
+// function that created the root error (e.g. "repo.(*Users).Get") is used for them instead. The message is the full
+// error message, and the stack trace is the eris string output with trace (i.e. each wrap error with its frame
+// followed by the root error stack). The number of wrap errors in the chain is added to the event as the
+// "eris.wrap_count" attribute, the code of the error as the "eris.code" attribute, and each field of the error (see
+// eris.Fields) as an "eris.fields.<name>" attribute. Nothing is recorded for a nil error.
+func RecordErrorWithOptions(span trace.Span, err error, opts Options, options ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+
+	upErr := eris.Unpack(err)
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionType(errorType(err, upErr)),
+		semconv.ExceptionMessage(err.Error()),
+		semconv.ExceptionStacktrace(eris.ToString(err, true)),
+		attribute.Int("eris.wrap_count", len(upErr.ErrChain)),
+	}
+	codeFunc := opts.CodeFunc
+	if codeFunc == nil {
+		codeFunc = DefaultCode
+	}
+	if code, ok := codeFunc(err); ok {
+		attrs = append(attrs, attributeValue("eris.code", code))
+	}
+	fields := eris.Fields(err)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attrs = append(attrs, attribute.String("eris.fields."+name, fmt.Sprint(fields[name])))
+	}
+	span.AddEvent(semconv.ExceptionEventName, append(options, trace.WithAttributes(attrs...))...)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// DefaultCode returns the code of the first error in the chain that implements `StatusCode() int` (e.g. an HTTP status
+// code) and reports whether there is one. gRPC codes can be recorded with a CodeFunc that calls status.Code.
+func DefaultCode(err error) (interface{}, bool) {
+	var target interface{ StatusCode() int }
+	if eris.As(err, &target) {
+		return target.StatusCode(), true
+	}
+	return nil, false
+}
+
+// RegisterContextExtractors registers the "trace_id" and "span_id" context extractors, so errors created with
+// eris.NewCtx and eris.WrapCtx contain the IDs of the current span.
+func RegisterContextExtractors() {
+	eris.RegisterContextExtractor("trace_id", func(ctx context.Context) (interface{}, bool) {
+		sc := trace.SpanContextFromContext(ctx)
+		return sc.TraceID().String(), sc.HasTraceID()
+	})
+	eris.RegisterContextExtractor("span_id", func(ctx context.Context) (interface{}, bool) {
+		sc := trace.SpanContextFromContext(ctx)
+		return sc.SpanID().String(), sc.HasSpanID()
+	})
+}
+
+// errorType returns the type name of the root cause of an error or the name of the function that created the root
+// error if the cause is an eris error.
+func errorType(err error, upErr eris.UnpackedError) string {
+	if upErr.ErrExternal == nil && len(upErr.ErrRoot.Stack) > 0 {
+		return upErr.ErrRoot.Stack[0].Name
+	}
+	return fmt.Sprintf("%T", eris.Cause(err))
+}
+
+// attributeValue returns an attribute for a code, which is kept as an integer or string if it has one of these types.
+func attributeValue(key string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case string:
+		return attribute.String(key, v)
+	}
+	return attribute.String(key, fmt.Sprint(v))
+}
@@ -0,0 +1,177 @@
+package erisotel_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erisotel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordError(t *testing.T, err error) tracetest.SpanStub {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("erisotel_test").Start(context.Background(), "test")
+	erisotel.RecordError(span, err)
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 exported span, got %v", len(spans))
+	}
+	return spans[0]
+}
+
+func eventAttrs(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, event := range span.Events {
+		if event.Name != "exception" {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+	}
+	return attrs
+}
+
+func newRootError() error {
+	return eris.New("root error")
+}
+
+func TestRecordError(t *testing.T) {
+	tests := map[string]struct {
+		input     error
+		errType   string
+		message   string
+		wrapCount int64
+	}{
+		"root error": {
+			input:     newRootError(),
+			errType:   "erisotel_test.newRootError",
+			message:   "root error",
+			wrapCount: 0,
+		},
+		"wrapped error": {
+			input:     eris.Wrap(eris.Wrap(newRootError(), "additional context"), "even more context"),
+			errType:   "erisotel_test.newRootError",
+			message:   "even more context: additional context: root error",
+			wrapCount: 2,
+		},
+		"wrapped external error": {
+			input:     eris.Wrap(errors.New("external error"), "additional context"),
+			errType:   "*errors.errorString",
+			message:   "additional context: external error",
+			wrapCount: 0,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			span := recordError(t, tt.input)
+			if span.Status.Code != codes.Error || span.Status.Description != tt.message {
+				t.Errorf("expected span status { %v %v } got { %v %v }", codes.Error, tt.message, span.Status.Code, span.Status.Description)
+			}
+
+			attrs := eventAttrs(span)
+			if got := attrs["exception.type"].AsString(); got != tt.errType {
+				t.Errorf("expected exception.type { %v } got { %v }", tt.errType, got)
+			}
+			if got := attrs["exception.message"].AsString(); got != tt.message {
+				t.Errorf("expected exception.message { %v } got { %v }", tt.message, got)
+			}
+			if got := attrs["exception.stacktrace"].AsString(); !strings.Contains(got, "erisotel_test.TestRecordError") {
+				t.Errorf("expected exception.stacktrace to contain the test function, got { %v }", got)
+			}
+			if got := attrs["eris.wrap_count"].AsInt64(); got != tt.wrapCount {
+				t.Errorf("expected eris.wrap_count { %v } got { %v }", tt.wrapCount, got)
+			}
+		})
+	}
+}
+
+// statusError is an external error with an HTTP status code.
+type statusError struct {
+	status int
+}
+
+func (e statusError) Error() string   { return "status error" }
+func (e statusError) StatusCode() int { return e.status }
+
+func TestRecordErrorCode(t *testing.T) {
+	tests := map[string]struct {
+		input   error
+		options erisotel.Options
+		code    attribute.Value // expected eris.code attribute (empty if there's no code)
+	}{
+		"error without code": {
+			input: eris.Wrap(newRootError(), "additional context"),
+		},
+		"error with status code": {
+			input: eris.Wrap(statusError{status: 404}, "additional context"),
+			code:  attribute.IntValue(404),
+		},
+		"custom code": {
+			input: eris.Wrap(newRootError(), "additional context"),
+			options: erisotel.Options{CodeFunc: func(error) (interface{}, bool) {
+				return "NOT_FOUND", true
+			}},
+			code: attribute.StringValue("NOT_FOUND"),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			_, span := provider.Tracer("erisotel_test").Start(context.Background(), "test")
+			erisotel.RecordErrorWithOptions(span, tt.input, tt.options)
+			span.End()
+
+			attrs := eventAttrs(exporter.GetSpans()[0])
+			if got := attrs["eris.code"]; got != tt.code {
+				t.Errorf("expected eris.code { %v } got { %v }", tt.code.Emit(), got.Emit())
+			}
+		})
+	}
+}
+
+func TestRecordNilError(t *testing.T) {
+	span := recordError(t, nil)
+	if len(span.Events) != 0 {
+		t.Errorf("expected no span events, got %v", span.Events)
+	}
+	if span.Status.Code != codes.Unset {
+		t.Errorf("expected span status { %v } got { %v }", codes.Unset, span.Status.Code)
+	}
+}
+
+func TestRecordErrorWithContextFields(t *testing.T) {
+	erisotel.RegisterContextExtractors()
+	defer eris.RegisterContextExtractor("trace_id", nil)
+	defer eris.RegisterContextExtractor("span_id", nil)
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	ctx, span := provider.Tracer("erisotel_test").Start(context.Background(), "test")
+	erisotel.RecordError(span, eris.WrapCtx(ctx, eris.New("root error"), "additional context"))
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 exported span, got %v", len(spans))
+	}
+	attrs := eventAttrs(spans[0])
+	if got, want := attrs["eris.fields.trace_id"].AsString(), spans[0].SpanContext.TraceID().String(); got != want {
+		t.Errorf("expected eris.fields.trace_id { %v } got { %v }", want, got)
+	}
+	if got, want := attrs["eris.fields.span_id"].AsString(), spans[0].SpanContext.SpanID().String(); got != want {
+		t.Errorf("expected eris.fields.span_id { %v } got { %v }", want, got)
+	}
+}
@@ -0,0 +1,45 @@
+// Package erispkgerrors imports the stack traces of errors created with github.com/pkg/errors.
+package erispkgerrors
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rotisserie/eris"
+)
+
+// stackTracer is implemented by the errors of pkg/errors that record a stack trace.
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// StackFunc returns the stack of an error created with pkg/errors as a program counter slice. It can be registered
+// with eris.RegisterStackFunc.
+func StackFunc(err error) ([]uintptr, bool) {
+	st, ok := err.(stackTracer)
+	if !ok {
+		return nil, false
+	}
+	trace := st.StackTrace()
+	pcs := make([]uintptr, len(trace))
+	for i, f := range trace {
+		pcs[i] = uintptr(f)
+	}
+	return pcs, len(pcs) > 0
+}
+
+// Stack returns the stack of the innermost error in err's chain that was created with pkg/errors or nil if there
+// isn't one.
+func Stack(err error) eris.Stack {
+	var stack eris.Stack
+	for ; err != nil; err = eris.Unwrap(err) {
+		if pcs, ok := StackFunc(err); ok {
+			stack = eris.NewStack(pcs)
+		}
+	}
+	return stack
+}
+
+// Register registers StackFunc, so errors created with pkg/errors keep their original stack when they're wrapped
+// with eris.
+func Register() {
+	eris.RegisterStackFunc("pkg/errors", StackFunc)
+}
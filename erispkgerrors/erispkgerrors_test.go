@@ -0,0 +1,85 @@
+package erispkgerrors_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erispkgerrors"
+)
+
+func newError() error {
+	return errors.New("external error")
+}
+
+func TestStack(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		output string // name of the innermost frame (empty if there's no stack)
+	}{
+		"nil error": {
+			input:  nil,
+			output: "",
+		},
+		"error without stack": {
+			input:  fmt.Errorf("external error"),
+			output: "",
+		},
+		"error with stack": {
+			input:  newError(),
+			output: "erispkgerrors_test.newError",
+		},
+		"wrapped error with stack": {
+			input:  errors.Wrap(newError(), "additional context"),
+			output: "erispkgerrors_test.newError",
+		},
+		"standard wrapped error with stack": {
+			input:  fmt.Errorf("additional context: %w", newError()),
+			output: "erispkgerrors_test.newError",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			stack := erispkgerrors.Stack(tt.input)
+			if tt.output == "" {
+				if stack != nil {
+					t.Errorf("Stack() got { %v } want { nil }", stack)
+				}
+				return
+			}
+			if len(stack) == 0 || stack[0].Name != tt.output {
+				t.Fatalf("Stack() got { %v } want innermost frame { %v }", stack, tt.output)
+			}
+			for _, f := range stack {
+				if strings.HasPrefix(f.Name, "runtime.") {
+					t.Errorf("Stack() got runtime frame { %v }", f)
+				}
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	erispkgerrors.Register()
+	defer eris.RegisterStackFunc("pkg/errors", nil)
+
+	err := eris.Wrap(errors.Wrap(newError(), "additional context"), "even more context")
+	stack := eris.Unpack(err).ErrRoot.Stack
+	if len(stack) == 0 || stack[0].Name != "erispkgerrors_test.newError" {
+		t.Errorf("Wrap() got root stack { %v } want the stack of the external error", stack)
+	}
+	if str := eris.ToString(err, true); !strings.Contains(str, "erispkgerrors_test.newError") {
+		t.Errorf("ToString() got { %v } without the stack of the external error", str)
+	}
+}
+
+func TestBuiltInInterfaces(t *testing.T) {
+	// the stack is reused without registering StackFunc because eris recognizes the interface of the errors
+	err := eris.Wrap(newError(), "additional context")
+	stack := eris.Unpack(err).ErrRoot.Stack
+	if len(stack) == 0 || stack[0].Name != "erispkgerrors_test.newError" {
+		t.Errorf("Wrap() got root stack { %v } want the stack of the external error", stack)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/rotisserie/eris/erispkgerrors
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/rotisserie/eris v0.5.4
+)
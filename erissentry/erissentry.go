@@ -0,0 +1,170 @@
+// Package erissentry converts eris errors into Sentry events.
+package erissentry
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotisserie/eris"
+)
+
+// Options defines how errors are converted into Sentry events.
+type Options struct {
+	// ModulePath is the import path prefix of frames that are marked as in-app (e.g. "github.com/org/service"). If
+	// it's empty, the default heuristics of the Sentry SDK are used instead.
+	ModulePath string
+}
+
+// CaptureError converts an error into a Sentry event and sends it using the given hub. The current hub is used if
+// hub is nil. It returns the ID of the event or nil if the event wasn't sent.
+func CaptureError(hub *sentry.Hub, err error, options Options) *sentry.EventID {
+	if err == nil {
+		return nil
+	}
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	return hub.CaptureEvent(NewEvent(err, options))
+}
+
+// NewEvent returns a Sentry event for a given error.
+//
+// The event contains one exception per error in the chain: one for each wrap error with a message, one for the root
+// error, and one for the external error if there is one. Each exception has its own frames (i.e. a single frame for
+// wrap errors and the complete stack for root errors) and a chained mechanism that links it to the error that wraps it.
+// The fields of the errors in the chain (see eris.Fields) are added to the extra data of the event.
+//
+// eris errors don't have distinct types, so the type of an exception for an eris error is the name of the function
+// that created or wrapped it (e.g. "repo.(*Users).Get"). External errors keep their type name.
+func NewEvent(err error, options Options) *sentry.Event {
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	if err == nil {
+		return event
+	}
+
+	for k, v := range eris.Fields(err) {
+		event.Extra[k] = v
+	}
+
+	links := chain(err)
+	for i, l := range links {
+		exception := sentry.Exception{
+			Type:       l.typ,
+			Value:      l.msg,
+			Stacktrace: stacktrace(l.frames, options),
+			Mechanism: &sentry.Mechanism{
+				Type:        sentry.MechanismTypeGeneric,
+				ExceptionID: i,
+			},
+		}
+		if i > 0 {
+			parentID := i - 1
+			exception.Mechanism.Type = sentry.MechanismTypeChained
+			exception.Mechanism.Source = sentry.MechanismTypeUnwrap
+			exception.Mechanism.ParentID = &parentID
+		}
+		// Sentry expects the outermost error last
+		event.Exception = append([]sentry.Exception{exception}, event.Exception...)
+	}
+	return event
+}
+
+// link is a single error in the chain with its own message and frames (nil if the error doesn't have a stack trace).
+type link struct {
+	err    error
+	typ    string
+	msg    string
+	frames []runtime.Frame
+}
+
+// chain returns the errors in the chain from the outermost wrap error to the external error.
+func chain(err error) []link {
+	upErr := eris.Unpack(err)
+
+	// the merged stack of the outermost error contains the frames of the root error and all wrap errors
+	stack := runtimeFrames(eris.MergedStackFrames(err))
+
+	var links []link
+	for i := len(upErr.ErrChain) - 1; i >= 0; i-- {
+		// wrap errors without a message (see eris.WithStack) are skipped since their frames are part of the root stack
+		if eLink := upErr.ErrChain[i]; eLink.Msg != "" {
+			links = append(links, link{err: err, typ: eLink.Frame.Name, msg: eLink.Msg, frames: wrapFrames(stack, eLink.Frame)})
+		}
+		err = eris.Unwrap(err)
+	}
+	if upErr.ErrRoot.Msg != "" || len(upErr.ErrRoot.Stack) > 0 {
+		typ := fmt.Sprintf("%T", err)
+		if len(upErr.ErrRoot.Stack) > 0 {
+			typ = upErr.ErrRoot.Stack[0].Name
+		}
+		links = append(links, link{err: err, typ: typ, msg: upErr.ErrRoot.Msg, frames: stack})
+	}
+	if ext := upErr.ErrExternal; ext != nil {
+		links = append(links, link{
+			err:    ext,
+			typ:    fmt.Sprintf("%T", ext),
+			msg:    ext.Error(),
+			frames: runtimeFrames(externalStackFrames(ext)),
+		})
+	}
+	return links
+}
+
+// externalStackFrames returns the program counters of an external error that has a `StackFrames() []uintptr` method
+// (e.g. the errors of other libraries that Sentry supports) or nil.
+func externalStackFrames(err error) []uintptr {
+	if st, ok := err.(interface{ StackFrames() []uintptr }); ok {
+		return st.StackFrames()
+	}
+	return nil
+}
+
+// runtimeFrames returns the frames of a program counter slice or nil if it's empty.
+func runtimeFrames(pcs []uintptr) []runtime.Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var frames []runtime.Frame
+	callersFrames := runtime.CallersFrames(pcs)
+	for {
+		f, more := callersFrames.Next()
+		frames = append(frames, f)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// wrapFrames returns the single frame of a wrap error. The frame is looked up in the stack of the outermost error, so
+// it has the full function name, and it's built from the eris frame if it's not part of the stack.
+func wrapFrames(stack []runtime.Frame, frame eris.StackFrame) []runtime.Frame {
+	for _, f := range stack {
+		if f.File == frame.File && f.Line == frame.Line {
+			return []runtime.Frame{f}
+		}
+	}
+	return []runtime.Frame{{Function: frame.Name, File: frame.File, Line: frame.Line}}
+}
+
+// stacktrace returns the Sentry stack trace for the frames of a single error or nil if there are no frames.
+func stacktrace(stack []runtime.Frame, options Options) *sentry.Stacktrace {
+	if len(stack) == 0 {
+		return nil
+	}
+
+	var frames []sentry.Frame
+	for _, f := range stack {
+		frame := sentry.NewFrame(f)
+		if options.ModulePath != "" {
+			frame.InApp = frame.Module == options.ModulePath || strings.HasPrefix(frame.Module, options.ModulePath+"/")
+		}
+		// Sentry expects the oldest frame first
+		frames = append([]sentry.Frame{frame}, frames...)
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
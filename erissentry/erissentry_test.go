@@ -0,0 +1,134 @@
+package erissentry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erissentry"
+)
+
+func newRootError() error {
+	return eris.New("root error")
+}
+
+func TestNewEvent(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		types  []string // expected exception types (innermost first)
+		values []string // expected exception values (innermost first)
+		frames []int    // expected number of frames per exception (-1 for no stack trace)
+	}{
+		"root error": {
+			input:  newRootError(),
+			types:  []string{"erissentry_test.newRootError"},
+			values: []string{"root error"},
+		},
+		"wrapped error": {
+			input:  eris.Wrap(eris.Wrap(newRootError(), "additional context"), "even more context"),
+			types:  []string{"erissentry_test.newRootError", "erissentry_test.TestNewEvent", "erissentry_test.TestNewEvent"},
+			values: []string{"root error", "additional context", "even more context"},
+		},
+		"wrapped error with stack": {
+			input:  eris.WithStack(eris.Wrap(eris.WithStack(newRootError()), "additional context")),
+			types:  []string{"erissentry_test.newRootError", "erissentry_test.TestNewEvent"},
+			values: []string{"root error", "additional context"},
+		},
+		"wrapped external error": {
+			input:  eris.Wrap(eris.Wrap(errors.New("external error"), "additional context"), "even more context"),
+			types:  []string{"*errors.errorString", "erissentry_test.TestNewEvent", "erissentry_test.TestNewEvent"},
+			values: []string{"external error", "additional context", "even more context"},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			event := erissentry.NewEvent(tt.input, erissentry.Options{ModulePath: "github.com/rotisserie/eris"})
+			if len(event.Exception) != len(tt.types) {
+				t.Fatalf("expected %v exceptions, got %v", len(tt.types), len(event.Exception))
+			}
+			for i, exception := range event.Exception {
+				if exception.Type != tt.types[i] {
+					t.Errorf("expected exception type { %v } got { %v }", tt.types[i], exception.Type)
+				}
+				if exception.Value != tt.values[i] {
+					t.Errorf("expected exception value { %v } got { %v }", tt.values[i], exception.Value)
+				}
+
+				// exceptions are linked from the outermost error (ID 0) to the innermost error
+				id := len(event.Exception) - 1 - i
+				if exception.Mechanism == nil || exception.Mechanism.ExceptionID != id {
+					t.Fatalf("expected exception ID { %v } got { %v }", id, exception.Mechanism)
+				}
+				if id == 0 && (exception.Mechanism.Type != sentry.MechanismTypeGeneric || exception.Mechanism.ParentID != nil) {
+					t.Errorf("expected a generic mechanism for the outermost error, got { %+v }", exception.Mechanism)
+				}
+				if id > 0 && (exception.Mechanism.Type != sentry.MechanismTypeChained || *exception.Mechanism.ParentID != id-1) {
+					t.Errorf("expected a chained mechanism with parent { %v } got { %+v }", id-1, exception.Mechanism)
+				}
+
+				if exception.Type == "*errors.errorString" {
+					if exception.Stacktrace != nil {
+						t.Errorf("expected no stack trace for external errors, got { %v }", exception.Stacktrace)
+					}
+					continue
+				}
+				if exception.Stacktrace == nil || len(exception.Stacktrace.Frames) == 0 {
+					t.Fatalf("expected a stack trace for eris errors")
+				}
+				// the most recent frame is last and it's always in the test function or in newRootError
+				last := exception.Stacktrace.Frames[len(exception.Stacktrace.Frames)-1]
+				if (last.Function != "TestNewEvent" && last.Function != "newRootError") || !last.InApp {
+					t.Errorf("expected an in-app frame in the test file, got { %+v }", last)
+				}
+				for _, frame := range exception.Stacktrace.Frames {
+					if frame.Module == "testing" && frame.InApp {
+						t.Errorf("expected frames outside of the module path to not be in-app { %+v }", frame)
+					}
+				}
+			}
+		})
+	}
+}
+
+type ctxKey string
+
+func TestNewEventFields(t *testing.T) {
+	eris.RegisterContextExtractor("request_id", func(ctx context.Context) (interface{}, bool) {
+		v, ok := ctx.Value(ctxKey("request_id")).(string)
+		return v, ok
+	})
+	defer eris.RegisterContextExtractor("request_id", nil)
+
+	ctx := context.WithValue(context.Background(), ctxKey("request_id"), "req-7")
+	event := erissentry.NewEvent(eris.Wrap(eris.WrapCtx(ctx, eris.New("root error"), "additional context"), "even more context"), erissentry.Options{})
+	if event.Extra["request_id"] != "req-7" || len(event.Extra) != 1 {
+		t.Errorf("expected the error fields in the extra data, got { %v }", event.Extra)
+	}
+}
+
+func TestCaptureError(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:       "https://public@example.com/1",
+		Transport: transport,
+	})
+	if err != nil {
+		t.Fatalf("failed to create Sentry client: %v", err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+
+	if id := erissentry.CaptureError(hub, nil, erissentry.Options{}); id != nil {
+		t.Errorf("expected no event for a nil error, got { %v }", *id)
+	}
+	erissentry.CaptureError(hub, eris.Wrap(eris.New("root error"), "additional context"), erissentry.Options{})
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 sent event, got %v", len(events))
+	}
+	if len(events[0].Exception) != 2 {
+		t.Errorf("expected 2 exceptions, got %v", len(events[0].Exception))
+	}
+}
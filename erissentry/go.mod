@@ -0,0 +1,15 @@
+module github.com/rotisserie/eris/erissentry
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/getsentry/sentry-go v0.42.0
+	github.com/rotisserie/eris v0.5.4
+)
+
+require (
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
@@ -0,0 +1,83 @@
+// Package erizap provides zap fields that log eris errors with their stack traces.
+package erizap
+
+import (
+	"sort"
+
+	"github.com/rotisserie/eris"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Error returns a zap field with the key "error" that logs an error in the default JSON format with stack traces.
+func Error(err error) zap.Field {
+	return NamedError("error", err, eris.NewDefaultJSONFormat(eris.FormatOptions{
+		WithTrace:    true,
+		WithExternal: true,
+	}))
+}
+
+// NamedError returns a zap field that logs an error in a custom JSON format. The field is skipped if err is nil.
+func NamedError(key string, err error, format eris.JSONFormat) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, Marshaler(err, format))
+}
+
+// Marshaler returns a zap object marshaler that encodes an error with the same structure as eris.ToCustomJSON.
+func Marshaler(err error, format eris.JSONFormat) zapcore.ObjectMarshaler {
+	return errorMarshaler{err: err, format: format}
+}
+
+type errorMarshaler struct {
+	err    error
+	format eris.JSONFormat
+}
+
+func (m errorMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return objectMarshaler(eris.ToCustomJSON(m.err, m.format)).MarshalLogObject(enc)
+}
+
+// objectMarshaler encodes a map returned by eris.ToCustomJSON in sorted key order.
+type objectMarshaler map[string]interface{}
+
+func (m objectMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var err error
+		switch v := m[k].(type) {
+		case string:
+			enc.AddString(k, v)
+		case []string:
+			err = enc.AddArray(k, zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				for _, s := range v {
+					arr.AppendString(s)
+				}
+				return nil
+			}))
+		case map[string]interface{}:
+			err = enc.AddObject(k, objectMarshaler(v))
+		case []map[string]interface{}:
+			err = enc.AddArray(k, zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+				for _, obj := range v {
+					if err := arr.AppendObject(objectMarshaler(obj)); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		default:
+			err = enc.AddReflected(k, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package erizap_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erizap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newLogger(buf *bytes.Buffer) *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	return zap.New(zapcore.NewCore(encoder, zapcore.AddSync(buf), zapcore.DebugLevel))
+}
+
+func TestNamedError(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		format eris.JSONFormat
+	}{
+		"wrapped error": {
+			input:  eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{}),
+		},
+		"wrapped error with trace": {
+			input:  eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, InvertOutput: true}),
+		},
+		"external error": {
+			input:  eris.Wrap(errors.New("external error"), "additional context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, WithExternal: true}),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			newLogger(&buf).Error("failed", erizap.NamedError("err", tt.input, tt.format))
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal log entry: %v", err)
+			}
+			var want interface{}
+			result, _ := json.Marshal(eris.ToCustomJSON(tt.input, tt.format))
+			_ = json.Unmarshal(result, &want)
+			if !reflect.DeepEqual(got["err"], want) {
+				t.Errorf("expected { %v } got { %v }", want, got["err"])
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+	logger.Error("failed", erizap.Error(eris.Wrap(eris.New("root error"), "additional context")))
+	logger.Error("failed", erizap.Error(nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log entries, got %v", len(lines))
+	}
+	if !strings.Contains(lines[0], `"error":{"root":{"message":"root error","stack":[`) ||
+		!strings.Contains(lines[0], "erizap_test.TestError") {
+		t.Errorf("expected the error with a stack trace, got { %v }", lines[0])
+	}
+	if lines[1] != `{"msg":"failed"}` {
+		t.Errorf("expected nil errors to be skipped, got { %v }", lines[1])
+	}
+}
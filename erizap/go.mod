@@ -0,0 +1,12 @@
+module github.com/rotisserie/eris/erizap
+
+go 1.23.0
+
+replace github.com/rotisserie/eris => ../
+
+require (
+	github.com/rotisserie/eris v0.5.4
+	go.uber.org/zap v1.28.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
@@ -0,0 +1,82 @@
+// Package erizerolog provides zerolog marshalers that log eris errors with their stack traces.
+//
+// The marshalers are meant to be assigned to the global zerolog settings:
+//
+//   zerolog.ErrorStackMarshaler = erizerolog.MarshalStack
+//   zerolog.ErrorMarshalFunc = erizerolog.MarshalError
+package erizerolog
+
+import (
+	"sort"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// MarshalStack is a zerolog.ErrorStackMarshaler that logs errors in the default JSON format with stack traces.
+	MarshalStack = NewMarshalFunc(eris.NewDefaultJSONFormat(eris.FormatOptions{
+		WithTrace:    true,
+		WithExternal: true,
+	}))
+
+	// MarshalError is a zerolog.ErrorMarshalFunc that logs errors in the default JSON format without stack traces.
+	MarshalError = NewMarshalFunc(eris.NewDefaultJSONFormat(eris.FormatOptions{
+		WithExternal: true,
+	}))
+)
+
+// NewMarshalFunc returns a function that logs errors in a custom JSON format. It can be used as either a
+// zerolog.ErrorStackMarshaler or a zerolog.ErrorMarshalFunc.
+func NewMarshalFunc(format eris.JSONFormat) func(err error) interface{} {
+	return func(err error) interface{} {
+		if err == nil {
+			return nil
+		}
+		return Marshaler(err, format)
+	}
+}
+
+// Marshaler returns a zerolog object marshaler that encodes an error with the same structure as eris.ToCustomJSON.
+func Marshaler(err error, format eris.JSONFormat) zerolog.LogObjectMarshaler {
+	return errorMarshaler{err: err, format: format}
+}
+
+type errorMarshaler struct {
+	err    error
+	format eris.JSONFormat
+}
+
+func (m errorMarshaler) MarshalZerologObject(e *zerolog.Event) {
+	objectMarshaler(eris.ToCustomJSON(m.err, m.format)).MarshalZerologObject(e)
+}
+
+// objectMarshaler encodes a map returned by eris.ToCustomJSON in sorted key order.
+type objectMarshaler map[string]interface{}
+
+func (m objectMarshaler) MarshalZerologObject(e *zerolog.Event) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case string:
+			e.Str(k, v)
+		case []string:
+			e.Strs(k, v)
+		case map[string]interface{}:
+			e.Object(k, objectMarshaler(v))
+		case []map[string]interface{}:
+			arr := zerolog.Arr()
+			for _, obj := range v {
+				arr.Object(objectMarshaler(obj))
+			}
+			e.Array(k, arr)
+		default:
+			e.Interface(k, v)
+		}
+	}
+}
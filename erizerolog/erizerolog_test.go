@@ -0,0 +1,86 @@
+package erizerolog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+	"github.com/rotisserie/eris/erizerolog"
+	"github.com/rs/zerolog"
+)
+
+func TestMarshaler(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		format eris.JSONFormat
+	}{
+		"wrapped error": {
+			input:  eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{}),
+		},
+		"wrapped error with trace": {
+			input:  eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, InvertOutput: true}),
+		},
+		"external error": {
+			input:  eris.Wrap(errors.New("external error"), "additional context"),
+			format: eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, WithExternal: true}),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf)
+			logger.Error().Object("error", erizerolog.Marshaler(tt.input, tt.format)).Send()
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal log entry: %v", err)
+			}
+			var want interface{}
+			result, _ := json.Marshal(eris.ToCustomJSON(tt.input, tt.format))
+			_ = json.Unmarshal(result, &want)
+			if !reflect.DeepEqual(got["error"], want) {
+				t.Errorf("expected { %v } got { %v }", want, got["error"])
+			}
+		})
+	}
+}
+
+func TestGlobalMarshalers(t *testing.T) {
+	stackMarshaler, errorMarshalFunc := zerolog.ErrorStackMarshaler, zerolog.ErrorMarshalFunc
+	zerolog.ErrorStackMarshaler, zerolog.ErrorMarshalFunc = erizerolog.MarshalStack, erizerolog.MarshalError
+	defer func() {
+		zerolog.ErrorStackMarshaler, zerolog.ErrorMarshalFunc = stackMarshaler, errorMarshalFunc
+	}()
+
+	var buf bytes.Buffer
+	err := eris.Wrap(eris.New("root error"), "additional context")
+	logger := zerolog.New(&buf)
+	logger.Error().Stack().Err(err).Send()
+
+	var got struct {
+		Error struct {
+			Root map[string]interface{} `json:"root"`
+		} `json:"error"`
+		Stack struct {
+			Root map[string]interface{} `json:"root"`
+		} `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal log entry: %v", err)
+	}
+	if _, exists := got.Error.Root["stack"]; exists {
+		t.Errorf("expected no stack trace in the error field { %v }", got.Error)
+	}
+	if _, exists := got.Stack.Root["stack"]; !exists {
+		t.Errorf("expected a stack trace in the stack field { %v }", got.Stack)
+	}
+	if !strings.Contains(buf.String(), "erizerolog_test.TestGlobalMarshalers") {
+		t.Errorf("expected the stack trace to contain the test function { %v }", buf.String())
+	}
+}
@@ -1,7 +1,12 @@
 package eris
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // FormatOptions defines output options like omitting stack traces and inverting the error or stack order.
@@ -10,7 +15,9 @@ type FormatOptions struct {
 	WithTrace    bool // Flag that enables stack trace output.
 	InvertTrace  bool // Flag that inverts the stack trace output (top of call stack shown first).
 	WithExternal bool // Flag that enables external error output.
-	// todo: maybe allow users to hide wrap frames if desired
+	MergeTrace   bool // Flag that shows wrap error messages next to their frames in the root stack trace.
+	PublicOnly   bool // Flag that limits the output to the public error message (see PublicMessage).
+	SourceLines  int  // Number of source lines shown before and after each frame (see SetSourceLoader).
 }
 
 // StringFormat defines a string error format.
@@ -73,36 +80,125 @@ func ToString(err error, withTrace bool) string {
 //   [Format.PreStackSep]<Method2>[Format.StackElemSep]<File2>[Format.StackElemSep]<Line2>[Format.ErrorSep]
 //   [Format.PreStackSep]<Method1>[Format.StackElemSep]<File1>[Format.StackElemSep]<Line1>[Format.ErrorSep]
 func ToCustomString(err error, format StringFormat) string {
+	if format.Options.PublicOnly {
+		if err == nil {
+			return ""
+		}
+		return PublicMessage(err)
+	}
+
 	upErr := Unpack(err)
+	msgs, chain := upErr.mergeTrace(format.Options)
 
 	var str string
 	if format.Options.InvertOutput {
 		if format.Options.WithExternal && upErr.ErrExternal != nil {
-			str += formatExternalStr(upErr.ErrExternal, format.Options.WithTrace)
+			str += upErr.formatExternalStr(format)
 			if (format.Options.WithTrace && len(upErr.ErrRoot.Stack) > 0) || upErr.ErrRoot.Msg != "" {
 				str += format.ErrorSep
 			}
 		}
-		str += upErr.ErrRoot.formatStr(format)
-		for _, eLink := range upErr.ErrChain {
+		str += upErr.ErrRoot.formatStr(format, msgs)
+		for _, eLink := range chain {
 			str += format.ErrorSep + eLink.formatStr(format)
 		}
 	} else {
-		for i := len(upErr.ErrChain) - 1; i >= 0; i-- {
-			str += upErr.ErrChain[i].formatStr(format) + format.ErrorSep
+		for i := len(chain) - 1; i >= 0; i-- {
+			str += chain[i].formatStr(format) + format.ErrorSep
 		}
-		str += upErr.ErrRoot.formatStr(format)
+		str += upErr.ErrRoot.formatStr(format, msgs)
 		if format.Options.WithExternal && upErr.ErrExternal != nil {
 			if (format.Options.WithTrace && len(upErr.ErrRoot.Stack) > 0) || upErr.ErrRoot.Msg != "" {
 				str += format.ErrorSep
 			}
-			str += formatExternalStr(upErr.ErrExternal, format.Options.WithTrace)
+			str += upErr.formatExternalStr(format)
 		}
 	}
 
 	return str
 }
 
+// ToCausedByString returns a string for a given error in the style of a Java or Python traceback.
+//
+// The outermost error message is shown first, followed by each error in the chain as a "Caused by" section.
+// Frames that a section shares with the enclosing section are collapsed at the end of the section.
+//
+//   <Wrap error msg>
+//     at <Method2>(<File2>:<Line2>)
+//     at <Method1>(<File1>:<Line1>)
+//   Caused by: <Root error msg>
+//     at <Method3>(<File3>:<Line3>)
+//     ... 2 more
+func ToCausedByString(err error) string {
+	upErr := Unpack(err)
+	if upErr.ErrExternal == nil && upErr.ErrRoot.Msg == "" && len(upErr.ErrRoot.Stack) == 0 && len(upErr.ErrChain) == 0 {
+		return ""
+	}
+
+	// sections are ordered from the outermost wrap error down to the root error
+	var sections []causedBySection
+	for i := len(upErr.ErrChain) - 1; i >= 0; i-- {
+		link := upErr.ErrChain[i]
+		if link.Msg == "" {
+			// links without a message (see WithStack) only add a frame that's already part of other sections
+			continue
+		}
+		frames := []StackFrame{link.Frame}
+		if at := upErr.ErrRoot.Stack.index(link.Frame); at >= 0 {
+			frames = upErr.ErrRoot.Stack[at:]
+		}
+		sections = append(sections, causedBySection{msg: link.Msg, frames: frames})
+	}
+	if upErr.ErrRoot.Msg != "" || len(upErr.ErrRoot.Stack) > 0 {
+		sections = append(sections, causedBySection{msg: upErr.ErrRoot.Msg, frames: upErr.ErrRoot.Stack})
+	}
+	for i := len(upErr.ErrExternalChain) - 1; i >= 0; i-- {
+		eLink := upErr.ErrExternalChain[i]
+		switch {
+		case eLink.Msg != "":
+			sections = append(sections, causedBySection{msg: eLink.Msg, frames: eLink.Stack})
+		case len(eLink.Stack) > 0:
+			// layers without a message (e.g. pkg/errors.WithStack) are labeled by their type
+			sections = append(sections, causedBySection{msg: eLink.Type, frames: eLink.Stack})
+		}
+	}
+
+	var str string
+	var enclosing []StackFrame
+	for i, section := range sections {
+		if i > 0 {
+			str += "\nCaused by: "
+		}
+		str += section.msg
+		common := commonFrames(section.frames, enclosing)
+		for _, f := range section.frames[:len(section.frames)-common] {
+			str += "\n\tat " + f.formatCausedBy()
+		}
+		if common > 0 {
+			str += fmt.Sprintf("\n\t... %v more", common)
+		}
+		if len(section.frames) > 0 {
+			enclosing = section.frames
+		}
+	}
+	return str
+}
+
+// causedBySection is a single error message and its frames in a "Caused by" traceback.
+type causedBySection struct {
+	msg    string
+	frames []StackFrame
+}
+
+// commonFrames returns the number of frames at the bottom of the stack that two traces have in common.
+func commonFrames(frames, enclosing []StackFrame) int {
+	n := 0
+	for i, j := len(frames)-1, len(enclosing)-1; i >= 0 && j >= 0 && frames[i] == enclosing[j]; i, j = i-1, j-1 {
+		n++
+	}
+	return n
+}
+
 // JSONFormat defines a JSON error format.
 type JSONFormat struct {
 	Options FormatOptions // Format options (e.g. omitting stack trace or inverting the output order).
@@ -191,20 +287,35 @@ func ToJSON(err error, withTrace bool) map[string]interface{} {
 //     ]
 //   }
 func ToCustomJSON(err error, format JSONFormat) map[string]interface{} {
+	if format.Options.PublicOnly {
+		jsonMap := make(map[string]interface{})
+		if err != nil {
+			jsonMap["message"] = PublicMessage(err)
+		}
+		return jsonMap
+	}
+
 	upErr := Unpack(err)
+	msgs, chain := upErr.mergeTrace(format.Options)
 
 	jsonMap := make(map[string]interface{})
+	if upErr.ErrContext != nil {
+		jsonMap["context"] = upErr.ErrContext.Error()
+	}
 	if format.Options.WithExternal && upErr.ErrExternal != nil {
 		jsonMap["external"] = formatExternalStr(upErr.ErrExternal, format.Options.WithTrace)
+		if format.Options.WithTrace {
+			jsonMap["external_chain"] = upErr.formatExternalJSON(format)
+		}
 	}
 
 	if upErr.ErrRoot.Msg != "" || len(upErr.ErrRoot.Stack) > 0 {
-		jsonMap["root"] = upErr.ErrRoot.formatJSON(format)
+		jsonMap["root"] = upErr.ErrRoot.formatJSON(format, msgs)
 	}
 
-	if len(upErr.ErrChain) > 0 {
+	if len(chain) > 0 {
 		var wrapArr []map[string]interface{}
-		for _, eLink := range upErr.ErrChain {
+		for _, eLink := range chain {
 			wrapMap := eLink.formatJSON(format)
 			if format.Options.InvertOutput {
 				wrapArr = append(wrapArr, wrapMap)
@@ -221,18 +332,40 @@ func ToCustomJSON(err error, format JSONFormat) map[string]interface{} {
 // Unpack returns a human-readable UnpackedError type for a given error.
 func Unpack(err error) UnpackedError {
 	var upErr UnpackedError
+	var wrapPCs []*stack // callers of each wrap error in the chain, outermost first
 	for err != nil {
 		switch err := err.(type) {
 		case *rootError:
 			upErr.ErrRoot.Msg = err.msg
-			upErr.ErrRoot.Stack = err.stack.get()
+			upErr.ErrRoot.Public = err.public
+			upErr.ErrRoot.Retryable = err.retry == retryRetryable
+			upErr.ErrRoot.Permanent = err.retry == retryPermanent
+			upErr.ErrRoot.RetryAfter = err.retryAfter
+			upErr.ErrRoot.Fields = err.fields
+			upErr.ErrRoot.Stack = mergeStack(err.stack, wrapPCs).get()
+			upErr.setContextErr(err.ctxErr)
 		case *wrapError:
 			// prepend links in stack trace order
-			link := ErrLink{Msg: err.msg}
+			link := ErrLink{
+				Msg:        err.msg,
+				Public:     err.public,
+				Retryable:  err.retry == retryRetryable,
+				Permanent:  err.retry == retryPermanent,
+				RetryAfter: err.retryAfter,
+				Fields:     err.fields,
+			}
 			link.Frame = err.frame.get()
+			wrapPCs = append(wrapPCs, err.stack)
+			upErr.setContextErr(err.ctxErr)
 			upErr.ErrChain = append([]ErrLink{link}, upErr.ErrChain...)
 		default:
 			upErr.ErrExternal = err
+			upErr.ErrExternalChain = unpackExternal(err)
+			if Is(err, context.Canceled) {
+				upErr.setContextErr(context.Canceled)
+			} else if Is(err, context.DeadlineExceeded) {
+				upErr.setContextErr(context.DeadlineExceeded)
+			}
 			return upErr
 		}
 		err = Unwrap(err)
@@ -240,15 +373,150 @@ func Unpack(err error) UnpackedError {
 	return upErr
 }
 
+// Fingerprint returns an ID for errors with the same root error message and the same functions in the root error
+// stack, e.g. to group the errors in logs. Line numbers and wrap errors aren't part of the fingerprint, so it doesn't
+// change if unrelated lines of a file are edited or if the error is wrapped in other places. Errors without a root
+// error are identified by the external error message instead.
+func Fingerprint(upErr UnpackedError) string {
+	msg := upErr.ErrRoot.Msg
+	if msg == "" && len(upErr.ErrRoot.Stack) == 0 && upErr.ErrExternal != nil {
+		msg = upErr.ErrExternal.Error()
+	}
+	h := sha256.New()
+	h.Write([]byte(msg))
+	for i, f := range upErr.ErrRoot.Stack {
+		if i > 0 && isWrapFrame(upErr.ErrRoot.Stack[i-1], f, upErr.ErrChain) {
+			continue
+		}
+		h.Write([]byte{0})
+		h.Write([]byte(f.Name))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// isWrapFrame reports whether a frame of a root error stack was inserted for one of the wrap errors. The frames of wrap
+// errors are inserted after the frame of the same function that's already in the stack (see stack.insertPC), so a
+// frame that's the first in its function is always part of the root error stack.
+func isWrapFrame(prev, f StackFrame, chain []ErrLink) bool {
+	if prev.Name != f.Name {
+		return false
+	}
+	for _, link := range chain {
+		if link.Frame == f {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeStack returns a copy of a root error stack with the frames of its wrap errors (outermost first) inserted in the
+// order they were wrapped.
+func mergeStack(rootPCs *stack, wrapPCs []*stack) *stack {
+	merged := rootPCs.clone()
+	for i := len(wrapPCs) - 1; i >= 0; i-- {
+		if wrapPCs[i] != nil {
+			merged.insertPC(*wrapPCs[i])
+		}
+	}
+	return merged
+}
+
 // UnpackedError represents complete information about an error.
 //
 // This type can be used for custom error logging and parsing. Use `eris.Unpack` to build an UnpackedError
 // from any error type. The ErrChain and ErrRoot fields correspond to `wrapError` and `rootError` types,
-// respectively. If any other error type is unpacked, it will appear in the ExternalErr field.
+// respectively. If any other error type is unpacked, it will appear in the ExternalErr field, and each layer of the
+// external error (including any eris errors it wraps) will appear in the ErrExternalChain field. If the error was
+// caused by a canceled context or an exceeded deadline, ErrContext is set to context.Canceled or
+// context.DeadlineExceeded.
 type UnpackedError struct {
-	ErrExternal error
-	ErrRoot     ErrRoot
-	ErrChain    []ErrLink
+	ErrExternal      error
+	ErrExternalChain []ErrExternalLink
+	ErrContext       error
+	ErrRoot          ErrRoot
+	ErrChain         []ErrLink
+}
+
+// unpackExternal returns the layers of an external error in stack trace order (i.e. the innermost error first).
+func unpackExternal(err error) []ErrExternalLink {
+	var chain []ErrExternalLink
+	for err != nil {
+		next := Unwrap(err)
+		link := ErrExternalLink{
+			Msg:   externalMsg(err, next),
+			Type:  fmt.Sprintf("%T", err),
+			Stack: layerStack(err),
+		}
+		chain = append([]ErrExternalLink{link}, chain...)
+		err = next
+	}
+	return chain
+}
+
+// layerStack returns the stack of a layer of an external error. Wrap errors only have their own frame because the root
+// error stack is already part of the root error layer.
+func layerStack(err error) Stack {
+	if e, ok := err.(*wrapError); ok {
+		return Stack{e.frame.get()}
+	}
+	return externalStack(err)
+}
+
+// externalMsg returns the message of an external error without the message of the next error in the chain.
+func externalMsg(err, next error) string {
+	msg := err.Error()
+	if next == nil {
+		return msg
+	}
+	if nextMsg := next.Error(); strings.HasSuffix(msg, nextMsg) {
+		return strings.TrimRight(strings.TrimSuffix(msg, nextMsg), ": ")
+	}
+	return msg
+}
+
+// setContextErr sets the context error if it isn't set already.
+func (upErr *UnpackedError) setContextErr(err error) {
+	if upErr.ErrContext == nil && err != nil {
+		upErr.ErrContext = err
+	}
+}
+
+// mergeTrace returns the wrap error messages keyed by the position of their frames in the root error stack, along
+// with the wrap errors that still have to be printed separately. Wrap errors are only merged into the root error
+// stack if both the MergeTrace and WithTrace options are set. Wrap errors without a message (see WithStack) are
+// dropped if there's no trace to show their frames in.
+func (upErr *UnpackedError) mergeTrace(options FormatOptions) (map[int]string, []ErrLink) {
+	if !options.WithTrace {
+		var chain []ErrLink
+		for _, eLink := range upErr.ErrChain {
+			if eLink.Msg != "" {
+				chain = append(chain, eLink)
+			}
+		}
+		return nil, chain
+	}
+	if !options.MergeTrace {
+		return nil, upErr.ErrChain
+	}
+	msgs := make(map[int]string)
+	var chain []ErrLink
+	for _, eLink := range upErr.ErrChain {
+		at := upErr.ErrRoot.Stack.index(eLink.Frame)
+		if at < 0 {
+			chain = append(chain, eLink)
+			continue
+		}
+		if eLink.Msg == "" {
+			continue
+		}
+		if msg, ok := msgs[at]; ok {
+			// the chain is in stack trace order, so later links wrap earlier ones
+			msgs[at] = eLink.Msg + ": " + msg
+		} else {
+			msgs[at] = eLink.Msg
+		}
+	}
+	return msgs, chain
 }
 
 // String formatter for external errors.
@@ -259,19 +527,96 @@ func formatExternalStr(err error, withTrace bool) string {
 	return fmt.Sprint(err)
 }
 
+// ErrExternalLink represents a single layer of an external error.
+type ErrExternalLink struct {
+	Msg   string // Message of this error without the message of the next error in the chain.
+	Type  string // Type name of the error (e.g. *errors.errorString).
+	Stack Stack  // Stack of the error if it exposes one (e.g. errors created with pkg/errors).
+}
+
+// String formatter for external error chains. The chain is only formatted layer by layer if it contains a stack,
+// otherwise the external error is formatted as a whole, so external types can still print details with '%+v'.
+func (upErr *UnpackedError) formatExternalStr(format StringFormat) string {
+	if !format.Options.WithTrace || !upErr.hasExternalStack() {
+		return formatExternalStr(upErr.ErrExternal, format.Options.WithTrace)
+	}
+	var strs []string
+	for _, eLink := range upErr.ErrExternalChain {
+		if eLink.Msg == "" && len(eLink.Stack) == 0 {
+			continue
+		}
+		root := ErrRoot{Msg: eLink.Msg, Stack: eLink.Stack}
+		str := root.formatStr(format, nil)
+		if len(eLink.Stack) == 0 {
+			str = eLink.Msg
+		}
+		if format.Options.InvertOutput {
+			strs = append(strs, str)
+		} else {
+			strs = append([]string{str}, strs...)
+		}
+	}
+	return strings.Join(strs, format.ErrorSep)
+}
+
+// hasExternalStack reports whether any layer of the external error has a stack.
+func (upErr *UnpackedError) hasExternalStack() bool {
+	for _, eLink := range upErr.ErrExternalChain {
+		if len(eLink.Stack) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON formatter for external error chains.
+func (upErr *UnpackedError) formatExternalJSON(format JSONFormat) []map[string]interface{} {
+	var extArr []map[string]interface{}
+	for _, eLink := range upErr.ErrExternalChain {
+		extMap := map[string]interface{}{
+			"message": eLink.Msg,
+			"type":    eLink.Type,
+		}
+		if len(eLink.Stack) > 0 {
+			extMap["stack"] = eLink.Stack.format(format.StackElemSep, format.Options.InvertTrace, nil)
+		}
+		if format.Options.InvertOutput {
+			extArr = append(extArr, extMap)
+		} else {
+			extArr = append([]map[string]interface{}{extMap}, extArr...)
+		}
+	}
+	return extArr
+}
+
 // ErrRoot represents an error stack and the accompanying message.
 type ErrRoot struct {
-	Msg   string
-	Stack Stack
+	Msg        string
+	Public     string
+	Retryable  bool
+	Permanent  bool
+	RetryAfter time.Duration
+	Fields     map[string]interface{}
+	Stack      Stack
 }
 
 // String formatter for root errors.
-func (err *ErrRoot) formatStr(format StringFormat) string {
-	str := err.Msg + format.MsgStackSep
+func (err *ErrRoot) formatStr(format StringFormat, msgs map[int]string) string {
+	var str string
+	if err.Msg != "" || !format.Options.WithTrace {
+		str = err.Msg + format.MsgStackSep
+	}
 	if format.Options.WithTrace {
-		stackArr := err.Stack.format(format.StackElemSep, format.Options.InvertTrace)
+		stackArr := err.Stack.format(format.StackElemSep, format.Options.InvertTrace, msgs)
 		for i, frame := range stackArr {
 			str += format.PreStackSep + frame
+			if format.Options.SourceLines > 0 {
+				f := err.Stack[len(stackArr)-1-i]
+				if format.Options.InvertTrace {
+					f = err.Stack[i]
+				}
+				str += f.formatSourceStr(format)
+			}
 			if i < len(stackArr)-1 {
 				str += format.ErrorSep
 			}
@@ -281,26 +626,54 @@ func (err *ErrRoot) formatStr(format StringFormat) string {
 }
 
 // JSON formatter for root errors.
-func (err *ErrRoot) formatJSON(format JSONFormat) map[string]interface{} {
+func (err *ErrRoot) formatJSON(format JSONFormat, msgs map[int]string) map[string]interface{} {
 	rootMap := make(map[string]interface{})
 	rootMap["message"] = fmt.Sprint(err.Msg)
+	if err.Public != "" {
+		rootMap["public"] = err.Public
+	}
+	formatRetryJSON(rootMap, err.Retryable, err.Permanent, err.RetryAfter)
+	if len(err.Fields) > 0 {
+		rootMap["fields"] = err.Fields
+	}
 	if format.Options.WithTrace {
-		rootMap["stack"] = err.Stack.format(format.StackElemSep, format.Options.InvertTrace)
+		rootMap["stack"] = err.Stack.format(format.StackElemSep, format.Options.InvertTrace, msgs)
+		if source := err.Stack.formatSourceJSON(format); len(source) > 0 {
+			rootMap["source"] = source
+		}
 	}
 	return rootMap
 }
 
+// JSON formatter for retry classifications.
+func formatRetryJSON(jsonMap map[string]interface{}, retryable, permanent bool, retryAfter time.Duration) {
+	if retryable || permanent {
+		jsonMap["retryable"] = retryable
+	}
+	if retryAfter > 0 {
+		jsonMap["retry_after"] = retryAfter.String()
+	}
+}
+
 // ErrLink represents a single error frame and the accompanying message.
 type ErrLink struct {
-	Msg   string
-	Frame StackFrame
+	Msg        string
+	Public     string
+	Retryable  bool
+	Permanent  bool
+	RetryAfter time.Duration
+	Fields     map[string]interface{}
+	Frame      StackFrame
 }
 
 // String formatter for wrap errors chains.
 func (eLink *ErrLink) formatStr(format StringFormat) string {
-	str := eLink.Msg + format.MsgStackSep
+	var str string
+	if eLink.Msg != "" || !format.Options.WithTrace {
+		str = eLink.Msg + format.MsgStackSep
+	}
 	if format.Options.WithTrace {
-		str += format.PreStackSep + eLink.Frame.format(format.StackElemSep)
+		str += format.PreStackSep + eLink.Frame.format(format.StackElemSep) + eLink.Frame.formatSourceStr(format)
 	}
 	return str
 }
@@ -309,8 +682,18 @@ func (eLink *ErrLink) formatStr(format StringFormat) string {
 func (eLink *ErrLink) formatJSON(format JSONFormat) map[string]interface{} {
 	wrapMap := make(map[string]interface{})
 	wrapMap["message"] = fmt.Sprint(eLink.Msg)
+	if eLink.Public != "" {
+		wrapMap["public"] = eLink.Public
+	}
+	formatRetryJSON(wrapMap, eLink.Retryable, eLink.Permanent, eLink.RetryAfter)
+	if len(eLink.Fields) > 0 {
+		wrapMap["fields"] = eLink.Fields
+	}
 	if format.Options.WithTrace {
 		wrapMap["stack"] = eLink.Frame.format(format.StackElemSep)
+		if source := eLink.Frame.source(format.Options.SourceLines); len(source) > 0 {
+			wrapMap["source"] = source
+		}
 	}
 	return wrapMap
 }
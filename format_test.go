@@ -3,8 +3,12 @@ package eris_test
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rotisserie/eris"
 )
@@ -301,3 +305,437 @@ func TestFormatJSONWithStack(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatCausedBy(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		output []string // expected output lines (excluding the frames of the outermost error)
+	}{
+		"local wrapped error": {
+			input: ProcessFile("example.json", false, false),
+			output: []string{
+				"Caused by: error reading file 'example.json'",
+				"\tat " + readFunc + "(" + file + ":41)",
+				"\tat " + parseFunc + "(" + file + ":46)",
+				"\tat " + processFunc + "(" + file + ":56)",
+				"\t... 2 more",
+				"Caused by: unexpected EOF",
+				"\tat " + readFunc + "(" + file + ":33)",
+				"\t... 5 more",
+			},
+		},
+		"external wrapped error": {
+			input: ProcessFile("example.json", false, true),
+			output: []string{
+				"Caused by: error reading file 'example.json'",
+				"\tat " + readFunc + "(" + file + ":41)",
+				"\tat " + parseFunc + "(" + file + ":46)",
+				"\tat " + processFunc + "(" + file + ":56)",
+				"\t... 2 more",
+				"Caused by: external context",
+				"Caused by: external error",
+			},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			lines := strings.Split(eris.ToCausedByString(tt.input), "\n")
+			if len(lines) != len(tt.output)+3 {
+				t.Fatalf("ToCausedByString() got %v lines, want %v", len(lines), len(tt.output)+3)
+			}
+			if lines[0] != "error processing file 'example.json'" {
+				t.Errorf("ToCausedByString() got\n'%v'\nwant\n'%v'", lines[0], "error processing file 'example.json'")
+			}
+			if !strings.HasPrefix(lines[1], "\tat "+processFunc+"(") || !strings.HasSuffix(lines[1], ":58)") {
+				t.Errorf("ToCausedByString() got unexpected wrap frame '%v'", lines[1])
+			}
+			for i, want := range tt.output {
+				got := lines[i+3]
+				if strings.HasPrefix(want, "\tat ") {
+					// only compare the suffix of the file path since it depends on the build environment
+					prefix, suffix, _ := strings.Cut(want, "(")
+					if !strings.HasPrefix(got, prefix+"(") || !strings.HasSuffix(got, suffix) {
+						t.Errorf("ToCausedByString() got\n'%v'\nwant\n'%v'", got, want)
+					}
+				} else if got != want {
+					t.Errorf("ToCausedByString() got\n'%v'\nwant\n'%v'", got, want)
+				}
+			}
+		})
+	}
+
+	if got := eris.ToCausedByString(errors.New("external error")); got != "external error" {
+		t.Errorf("ToCausedByString() got\n'%v'\nwant\n'%v'", got, "external error")
+	}
+	if got := eris.ToCausedByString(nil); got != "" {
+		t.Errorf("ToCausedByString() got\n'%v'\nwant\n'%v'", got, "")
+	}
+}
+
+func TestFormatMergeTrace(t *testing.T) {
+	err := ProcessFile("example.json", false, false)
+
+	format := eris.NewDefaultStringFormat(eris.FormatOptions{
+		WithTrace:   true,
+		InvertTrace: true,
+		MergeTrace:  true,
+	})
+	lines := strings.Split(eris.ToCustomString(err, format), "\n")
+	expected := []struct {
+		line int
+		msg  string
+	}{
+		{line: 33, msg: ""},
+		{line: 41, msg: " (error reading file 'example.json')"},
+		{line: 46, msg: ""},
+		{line: 56, msg: ""},
+		{line: 58, msg: " (error processing file 'example.json')"},
+	}
+	if len(lines) != len(expected)+2 {
+		t.Fatalf("ToCustomString() got %v lines, want %v:\n%v", len(lines), len(expected)+2, strings.Join(lines, "\n"))
+	}
+	if lines[0] != "unexpected EOF" {
+		t.Errorf("ToCustomString() got\n'%v'\nwant\n'%v'", lines[0], "unexpected EOF")
+	}
+	for i, want := range expected {
+		if suffix := fmt.Sprintf("%v:%v%v", file, want.line, want.msg); !strings.HasSuffix(lines[i+1], suffix) {
+			t.Errorf("ToCustomString() got\n'%v'\nwant suffix\n'%v'", lines[i+1], suffix)
+		}
+	}
+
+	jsonFormat := eris.NewDefaultJSONFormat(eris.FormatOptions{
+		WithTrace:  true,
+		MergeTrace: true,
+	})
+	errJSON := eris.ToCustomJSON(err, jsonFormat)
+	if _, exists := errJSON["wrap"]; exists {
+		t.Errorf("expected wrap errors to be merged into the root stack { %v }", errJSON)
+	}
+	rootMap, ok := errJSON["root"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected root error is malformed { %v }", errJSON)
+	}
+	stack, _ := rootMap["stack"].([]string)
+	if len(stack) != len(expected)+1 {
+		t.Fatalf("expected number of root stack frames { %v } got { %v }", len(expected)+1, len(stack))
+	}
+	if !strings.HasSuffix(stack[1], ":58 (error processing file 'example.json')") {
+		t.Errorf("expected wrap message in root stack frame, got { %v }", stack[1])
+	}
+
+	// merging has no effect on output without trace
+	format.Options.WithTrace = false
+	format = eris.NewDefaultStringFormat(format.Options)
+	if got, want := eris.ToCustomString(err, format), eris.ToString(err, false); got != want {
+		t.Errorf("ToCustomString() got\n'%v'\nwant\n'%v'", got, want)
+	}
+}
+
+func TestFormatPublic(t *testing.T) {
+	err := eris.Wrap(eris.WithPublicMessage(eris.New("root error"), "public error"), "additional context")
+
+	format := eris.NewDefaultStringFormat(eris.FormatOptions{WithTrace: true, PublicOnly: true})
+	if got := eris.ToCustomString(err, format); got != "public error" {
+		t.Errorf("ToCustomString() got\n'%v'\nwant\n'%v'", got, "public error")
+	}
+	result, _ := json.Marshal(eris.ToCustomJSON(err, eris.NewDefaultJSONFormat(eris.FormatOptions{PublicOnly: true})))
+	if got := string(result); got != `{"message":"public error"}` {
+		t.Errorf("ToCustomJSON() got %v want %v", got, `{"message":"public error"}`)
+	}
+
+	// the internal view shows public messages next to the internal ones
+	result, _ = json.Marshal(eris.ToJSON(err, false))
+	want := `{"root":{"message":"root error","public":"public error"},"wrap":[{"message":"additional context"}]}`
+	if got := string(result); got != want {
+		t.Errorf("ToJSON() got %v want %v", got, want)
+	}
+	if got := eris.Unpack(err).ErrRoot.Public; got != "public error" {
+		t.Errorf("Unpack() got public message { %v } want { %v }", got, "public error")
+	}
+}
+
+// frame and stackTrace mirror the stack types of pkg/errors.
+type (
+	frame      uintptr
+	stackTrace []frame
+)
+
+// stackError is an external error with a stack like the errors created with pkg/errors.
+type stackError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &stackError{msg: msg, stack: pcs[:n]}
+}
+
+func (e *stackError) Error() string {
+	return e.msg
+}
+
+func (e *stackError) StackTrace() stackTrace {
+	st := make(stackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		st[i] = frame(pc)
+	}
+	return st
+}
+
+func TestUnpackExternal(t *testing.T) {
+	type link struct {
+		msg   string
+		typ   string
+		stack bool
+	}
+	tests := map[string]struct {
+		input  error
+		output []link // expected external chain in stack trace order
+	}{
+		"external error": {
+			input: eris.Wrap(errors.New("external error"), "additional context"),
+			output: []link{
+				{msg: "external error", typ: "*errors.errorString"},
+			},
+		},
+		"external error with stack": {
+			input: eris.Wrap(fmt.Errorf("external context: %w", newStackError("external error")), "additional context"),
+			output: []link{
+				{msg: "external error", typ: "*eris_test.stackError", stack: true},
+				{msg: "external context", typ: "*fmt.wrapError"},
+			},
+		},
+		"external error wrapping eris error": {
+			input: eris.Wrap(fmt.Errorf("external context: %w", eris.Wrap(eris.New("root error"), "internal context")), "additional context"),
+			output: []link{
+				{msg: "root error", typ: "*eris.rootError", stack: true},
+				{msg: "internal context", typ: "*eris.wrapError", stack: true},
+				{msg: "external context", typ: "*fmt.wrapError"},
+			},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			chain := eris.Unpack(tt.input).ErrExternalChain
+			if len(chain) != len(tt.output) {
+				t.Fatalf("Unpack() got %v external links want %v: %+v", len(chain), len(tt.output), chain)
+			}
+			for i, want := range tt.output {
+				got := chain[i]
+				if got.Msg != want.msg || got.Type != want.typ || (len(got.Stack) > 0) != want.stack {
+					t.Errorf("Unpack() external link %v got { %v %v %v } want { %v %v %v }", i, got.Msg, got.Type, len(got.Stack), want.msg, want.typ, want.stack)
+				}
+				for _, f := range got.Stack {
+					if strings.HasPrefix(f.Name, "runtime.") {
+						t.Errorf("Unpack() got runtime frame %v in external link %v", f, i)
+					}
+				}
+			}
+		})
+	}
+
+	// the external stack is part of the trace
+	err := eris.Wrap(newStackError("external error"), "additional context")
+	if str := eris.ToString(err, true); !strings.Contains(str, "eris_test.TestUnpackExternal:") {
+		t.Errorf("ToString() got { %v } without the external stack", str)
+	}
+
+	// eris errors in an external chain only show their own frames, the root error stack is shown for the root error
+	// and the error that wraps the external error
+	root := eris.New("root error")
+	err = eris.Wrap(fmt.Errorf("external context: %w", eris.Wrap(root, "internal context")), "additional context")
+	if chain := eris.Unpack(err).ErrExternalChain; len(chain) != 3 || len(chain[1].Stack) != 1 {
+		t.Errorf("Unpack() got external chain %+v want a single frame for the wrap error", chain)
+	}
+	rootFrame := eris.Unpack(root).ErrRoot.Stack[0]
+	rootLine := fmt.Sprintf("%v:%v", rootFrame.File, rootFrame.Line)
+	if str := eris.ToString(err, true); strings.Count(str, rootLine) != 2 {
+		t.Errorf("ToString() got { %v } want the root error stack twice", str)
+	}
+}
+
+// callersError is an external error that exposes its stack with a custom method.
+type callersError struct {
+	pcs []uintptr
+}
+
+func (e *callersError) Error() string {
+	return "callers error"
+}
+
+func (e *callersError) PCs() []uintptr {
+	return e.pcs
+}
+
+// goError is an external error that exposes its stack like the errors created with go-errors.
+type goError struct {
+	pcs []uintptr
+}
+
+func (e *goError) Error() string {
+	return "go error"
+}
+
+func (e *goError) Callers() []uintptr {
+	return e.pcs
+}
+
+// sentryError is an external error that exposes its stack like the errors that are supported by Sentry.
+type sentryError struct {
+	pcs []uintptr
+}
+
+func (e *sentryError) Error() string {
+	return "sentry error"
+}
+
+func (e *sentryError) StackFrames() []uintptr {
+	return e.pcs
+}
+
+func TestExternalStackInterfaces(t *testing.T) {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	tests := map[string]struct {
+		input error
+		stack eris.Stack // expected stack of the external error
+	}{
+		"StackTrace method": {
+			input: &stackError{msg: "stack error", stack: pcs},
+			stack: eris.NewStack(pcs),
+		},
+		"Callers method": {
+			input: &goError{pcs: pcs},
+			stack: eris.NewStack(pcs),
+		},
+		"StackFrames method": {
+			input: &sentryError{pcs: pcs},
+			stack: eris.NewStack(pcs),
+		},
+		"no stack": {
+			input: &callersError{pcs: pcs},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			chain := eris.Unpack(eris.Wrap(tt.input, "additional context")).ErrExternalChain
+			if len(chain) != 1 {
+				t.Fatalf("Unpack() got %v external links want 1: %+v", len(chain), chain)
+			}
+			if !reflect.DeepEqual(chain[0].Stack, tt.stack) {
+				t.Errorf("Unpack() got external stack { %v } want { %v }", chain[0].Stack, tt.stack)
+			}
+		})
+	}
+}
+
+func newExternalStackError() error {
+	return fmt.Errorf("external context: %w", newStackError("external error"))
+}
+
+func TestExternalStack(t *testing.T) {
+	// wrapping an external error reuses its stack
+	err := eris.Wrap(newExternalStackError(), "additional context")
+	stack := eris.Unpack(err).ErrRoot.Stack
+	if len(stack) < 2 || stack[0].Name != "eris_test.newExternalStackError" || stack[1].Name != "eris_test.TestExternalStack" {
+		t.Errorf("Wrap() got root stack { %v } want the stack of the external error", stack)
+	}
+	for _, f := range stack {
+		if strings.HasPrefix(f.Name, "runtime.") {
+			t.Errorf("Wrap() got runtime frame %v in root stack", f)
+		}
+	}
+
+	// registered stack functions are used for types the built-in interfaces don't recognize
+	eris.RegisterStackFunc("callers", func(err error) ([]uintptr, bool) {
+		if e, ok := err.(*callersError); ok {
+			return e.PCs(), true
+		}
+		return nil, false
+	})
+	defer eris.RegisterStackFunc("callers", nil)
+	pcs := make([]uintptr, 32)
+	ext := &callersError{pcs: pcs[:runtime.Callers(1, pcs)]}
+	stack = eris.Unpack(eris.Wrap(ext, "additional context")).ErrRoot.Stack
+	if want := eris.NewStack(ext.pcs); len(stack) < len(want) || !reflect.DeepEqual(stack[:len(want)], want) {
+		t.Errorf("Wrap() got root stack { %v } want { %v }", stack, eris.NewStack(ext.pcs))
+	}
+
+	// stack functions can register other stack functions without a deadlock
+	eris.RegisterStackFunc("callers", func(err error) ([]uintptr, bool) {
+		eris.RegisterStackFunc("nested", nil)
+		return nil, false
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = eris.Wrap(ext, "additional context")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wrap() didn't return after registering a stack function in a stack function")
+	}
+
+	eris.RegisterStackFunc("callers", nil)
+	stack = eris.Unpack(eris.Wrap(ext, "additional context")).ErrRoot.Stack
+	if want := eris.NewStack(ext.pcs); len(stack) >= len(want) && reflect.DeepEqual(stack[:len(want)], want) {
+		t.Errorf("Wrap() got the stack of the external error after removing the stack function")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	newErr := func(msg string) error {
+		return eris.New(msg)
+	}
+	tests := map[string]struct {
+		a, b error
+		same bool // flag indicating whether the errors have the same fingerprint
+	}{
+		"same root error": {
+			a:    eris.Wrap(newErr("root error"), "additional context"),
+			b:    eris.Wrap(newErr("root error"), "other context"),
+			same: true,
+		},
+		"wrapped and unwrapped root error": {
+			a:    eris.Wrap(eris.WithStack(newErr("root error")), "additional context"),
+			b:    newErr("root error"),
+			same: true,
+		},
+		"wrapped global root error": {
+			a:    eris.Wrap(globalErr, "additional context"),
+			b:    eris.Wrap(eris.Wrap(globalErr, "additional context"), "even more context"),
+			same: true,
+		},
+		"different messages": {
+			a: newErr("root error"),
+			b: newErr("other error"),
+		},
+		"different functions": {
+			a: newErr("root error"),
+			b: eris.New("root error"),
+		},
+		"same external error": {
+			a:    errors.New("external error"),
+			b:    errors.New("external error"),
+			same: true,
+		},
+		"different external errors": {
+			a: errors.New("external error"),
+			b: errors.New("other error"),
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			a, b := eris.Fingerprint(eris.Unpack(tt.a)), eris.Fingerprint(eris.Unpack(tt.b))
+			if len(a) != 16 || strings.Trim(a, "0123456789abcdef") != "" {
+				t.Errorf("Fingerprint() got %q want 16 hex digits", a)
+			}
+			if same := a == b; same != tt.same {
+				t.Errorf("Fingerprint() got same = %v want %v", same, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package eris
+
+import "reflect"
+
+// AsType finds the first error in err's chain that has type T. If there's a match, it returns that error and true.
+// Otherwise, it returns the zero value of T and false.
+//
+// AsType follows the same rules as As (i.e. root and wrap errors are only matched through their As methods), but it
+// uses a type assertion instead of reflection to match each error in the chain:
+//
+//   if target, ok := eris.AsType[*NotFoundError](err); ok {
+//     // target is the *NotFoundError in the chain
+//   }
+func AsType[T error](err error) (T, bool) {
+	for err != nil {
+		if !isErisType(err) {
+			if target, ok := err.(T); ok {
+				return target, true
+			}
+		}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok {
+			var target T
+			if x.As(&target) {
+				return target, true
+			}
+		}
+		err = Unwrap(err)
+	}
+	var zero T
+	return zero, false
+}
+
+// Find returns all errors of type T in err's chain, ordered from the top of the chain. Unlike AsType, Find also
+// searches each branch of errors that implement `Unwrap() []error` (e.g. errors created with errors.Join).
+func Find[T error](err error) []T {
+	var found []T
+	walkTree(err, 0, func(e error, _ int) bool {
+		if isErisType(e) {
+			return true
+		}
+		if target, ok := e.(T); ok {
+			found = append(found, target)
+		}
+		return true
+	})
+	return found
+}
+
+// IsAny reports whether any error in err's chain matches any of the targets. It's equivalent to calling Is for each
+// target, but the chain is only traversed once.
+func IsAny(err error, targets ...error) bool {
+	isComparable := make([]bool, len(targets))
+	for i, target := range targets {
+		if target == nil {
+			if err == nil {
+				return true
+			}
+			continue
+		}
+		isComparable[i] = reflect.TypeOf(target).Comparable()
+	}
+	for err != nil {
+		x, hasIs := err.(interface{ Is(error) bool })
+		for i, target := range targets {
+			if target == nil {
+				continue
+			}
+			if isComparable[i] && err == target {
+				return true
+			}
+			if hasIs && x.Is(target) {
+				return true
+			}
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+// isErisType reports whether an error is a root or wrap error.
+func isErisType(err error) bool {
+	switch err.(type) {
+	case *rootError, *wrapError:
+		return true
+	}
+	return false
+}
+
+// walkTree calls fn for each error in err's tree in depth-first order along with the number of multi-error branches
+// that lead to it. The walk stops as soon as fn returns false, in which case walkTree returns false as well.
+func walkTree(err error, depth int, fn func(err error, depth int) bool) bool {
+	for err != nil {
+		if !fn(err, depth) {
+			return false
+		}
+		if u, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range u.Unwrap() {
+				if !walkTree(e, depth+1, fn) {
+					return false
+				}
+			}
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return true
+}
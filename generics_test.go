@@ -0,0 +1,139 @@
+package eris_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/rotisserie/eris"
+)
+
+type notFoundError struct {
+	id string
+}
+
+func (e *notFoundError) Error() string { return fmt.Sprintf("resource '%v' not found", e.id) }
+
+func TestAsType(t *testing.T) {
+	extErr := &notFoundError{id: "res1"}
+	tests := map[string]struct {
+		input error
+		match bool
+	}{
+		"nil error": {
+			input: nil,
+			match: false,
+		},
+		"root error": {
+			input: eris.Wrap(eris.New("root error"), "additional context"),
+			match: false,
+		},
+		"wrapped external error": {
+			input: eris.Wrap(eris.Wrap(extErr, "additional context"), "even more context"),
+			match: true,
+		},
+		"wrapped layered external error": {
+			input: eris.Wrap(fmt.Errorf("additional context: %w", extErr), "even more context"),
+			match: true,
+		},
+		"other external error": {
+			input: eris.Wrap(errors.New("external error"), "additional context"),
+			match: false,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			got, ok := eris.AsType[*notFoundError](tt.input)
+			if ok != tt.match {
+				t.Fatalf("AsType() got { %v } want { %v }", ok, tt.match)
+			}
+			if ok && got != extErr {
+				t.Errorf("AsType() got { %v } want { %v }", got, extErr)
+			}
+
+			// AsType must agree with As
+			var target *notFoundError
+			if ok := eris.As(tt.input, &target); ok != tt.match || target != got {
+				t.Errorf("As() got { %v %v } want { %v %v }", ok, target, tt.match, got)
+			}
+		})
+	}
+
+	// root and wrap errors can't be matched by type, only external errors
+	if got, ok := eris.AsType[error](eris.Wrap(io.EOF, "additional context")); !ok || got != io.EOF {
+		t.Errorf("AsType() got { %v %v } want { %v true }", got, ok, io.EOF)
+	}
+}
+
+// joinError mimics errors.Join (added in Go 1.20).
+type joinError []error
+
+func (e joinError) Error() string   { return fmt.Sprint([]error(e)) }
+func (e joinError) Unwrap() []error { return e }
+
+func TestFind(t *testing.T) {
+	first := &notFoundError{id: "res1"}
+	second := &notFoundError{id: "res2"}
+	third := &notFoundError{id: "res3"}
+
+	err := eris.Wrap(joinError{
+		fmt.Errorf("additional context: %w", first),
+		joinError{second, errors.New("external error")},
+		fmt.Errorf("even more context: %w", third),
+	}, "way too much context")
+	got := eris.Find[*notFoundError](err)
+	if len(got) != 3 || got[0] != first || got[1] != second || got[2] != third {
+		t.Errorf("Find() got { %v } want { %v %v %v }", got, first, second, third)
+	}
+
+	if got := eris.Find[*notFoundError](eris.New("root error")); len(got) != 0 {
+		t.Errorf("Find() got { %v } want { [] }", got)
+	}
+	if got := eris.Find[*notFoundError](nil); len(got) != 0 {
+		t.Errorf("Find() got { %v } want { [] }", got)
+	}
+}
+
+func TestIsAny(t *testing.T) {
+	errNotFound := eris.New("not found")
+	errConflict := eris.New("conflict")
+	tests := map[string]struct {
+		input   error
+		targets []error
+		match   bool
+	}{
+		"no targets": {
+			input:   eris.Wrap(errNotFound, "additional context"),
+			targets: nil,
+			match:   false,
+		},
+		"matching eris target": {
+			input:   eris.Wrap(errNotFound, "additional context"),
+			targets: []error{errConflict, errNotFound},
+			match:   true,
+		},
+		"matching external target": {
+			input:   eris.Wrap(io.EOF, "additional context"),
+			targets: []error{errConflict, io.EOF},
+			match:   true,
+		},
+		"no matching target": {
+			input:   eris.Wrap(io.EOF, "additional context"),
+			targets: []error{errConflict, io.ErrUnexpectedEOF},
+			match:   false,
+		},
+		"nil error and nil target": {
+			input:   nil,
+			targets: []error{io.EOF, nil},
+			match:   true,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.IsAny(tt.input, tt.targets...); got != tt.match {
+				t.Errorf("IsAny() got { %v } want { %v }", got, tt.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package eris
+
+import (
+	"html"
+	"strings"
+)
+
+// markupSection is a single error message and its formatted frames in a markup document.
+type markupSection struct {
+	msg    string
+	frames []string
+}
+
+// markupSections returns the sections of a markup document for a given error in output order.
+func markupSections(err error, options FormatOptions) []markupSection {
+	if options.PublicOnly {
+		return []markupSection{{msg: PublicMessage(err)}}
+	}
+
+	upErr := Unpack(err)
+	msgs, chain := upErr.mergeTrace(options)
+
+	var sections []markupSection
+	if options.WithExternal && upErr.ErrExternal != nil {
+		if options.WithTrace {
+			for _, eLink := range upErr.ErrExternalChain {
+				if eLink.Msg != "" || len(eLink.Stack) > 0 {
+					sections = append(sections, markupSection{msg: eLink.Msg, frames: eLink.Stack.format(":", options.InvertTrace, nil)})
+				}
+			}
+		} else {
+			sections = append(sections, markupSection{msg: formatExternalStr(upErr.ErrExternal, false)})
+		}
+	}
+	if upErr.ErrRoot.Msg != "" || len(upErr.ErrRoot.Stack) > 0 {
+		root := markupSection{msg: upErr.ErrRoot.Msg}
+		if options.WithTrace {
+			root.frames = upErr.ErrRoot.Stack.format(":", options.InvertTrace, msgs)
+		}
+		sections = append(sections, root)
+	}
+	for _, eLink := range chain {
+		link := markupSection{msg: eLink.Msg}
+		if options.WithTrace {
+			link.frames = []string{eLink.Frame.format(":")}
+		}
+		sections = append(sections, link)
+	}
+
+	if !options.InvertOutput {
+		for i, j := 0, len(sections)-1; i < j; i, j = i+1, j-1 {
+			sections[i], sections[j] = sections[j], sections[i]
+		}
+	}
+	return sections
+}
+
+// ToMarkdown returns a Markdown document for a given error, e.g. for pasting into an issue tracker.
+//
+// The error is rendered as a collapsible block that's summarized by the error message. Each error in the chain is
+// shown with its frames in a code block:
+//
+//   <details>
+//   <summary><Wrap error msg>: <Root error msg></summary>
+//
+//   **<Wrap error msg>**
+//
+//   ```
+//   <Method2>:<File2>:<Line2>
+//   ```
+//
+//   **<Root error msg>**
+//
+//   ```
+//   <Method2>:<File2>:<Line2>
+//   <Method1>:<File1>:<Line1>
+//   ```
+//
+//   </details>
+func ToMarkdown(err error, options FormatOptions) string {
+	if err == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<details>\n<summary>" + html.EscapeString(markupSummary(err, options)) + "</summary>\n")
+	for _, section := range markupSections(err, options) {
+		if section.msg != "" {
+			sb.WriteString("\n**" + escapeMarkdown(section.msg) + "**\n")
+		}
+		if len(section.frames) > 0 {
+			sb.WriteString("\n```\n" + strings.Join(section.frames, "\n") + "\n```\n")
+		}
+	}
+	sb.WriteString("\n</details>\n")
+	return sb.String()
+}
+
+// ToHTML returns a self-contained HTML fragment for a given error, e.g. for serving on a debug page.
+//
+// All messages and frames are escaped. Each error in the chain is rendered as an expandable section:
+//
+//   <div class="eris-error">
+//   <p class="eris-summary"><Wrap error msg>: <Root error msg></p>
+//   <details class="eris-link"><summary><Wrap error msg></summary><pre>
+//   <Method2>:<File2>:<Line2>
+//   </pre></details>
+//   <details class="eris-link"><summary><Root error msg></summary><pre>
+//   <Method2>:<File2>:<Line2>
+//   <Method1>:<File1>:<Line1>
+//   </pre></details>
+//   </div>
+func ToHTML(err error, options FormatOptions) string {
+	if err == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<div class=\"eris-error\">\n")
+	sb.WriteString("<p class=\"eris-summary\">" + html.EscapeString(markupSummary(err, options)) + "</p>\n")
+	for _, section := range markupSections(err, options) {
+		if len(section.frames) == 0 {
+			sb.WriteString("<p class=\"eris-link\">" + html.EscapeString(section.msg) + "</p>\n")
+			continue
+		}
+		sb.WriteString("<details class=\"eris-link\"><summary>" + html.EscapeString(section.msg) + "</summary><pre>\n")
+		for _, frame := range section.frames {
+			sb.WriteString(html.EscapeString(frame) + "\n")
+		}
+		sb.WriteString("</pre></details>\n")
+	}
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// markupSummary returns the single line error message used to summarize markup documents.
+func markupSummary(err error, options FormatOptions) string {
+	return ToCustomString(err, NewDefaultStringFormat(FormatOptions{
+		InvertOutput: options.InvertOutput,
+		WithExternal: options.WithExternal,
+		PublicOnly:   options.PublicOnly,
+	}))
+}
+
+// markdownEscaper escapes characters that have a special meaning in Markdown text.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `&lt;`, `>`, `&gt;`, `&`, `&amp;`, `#`, `\#`, `|`, `\|`,
+)
+
+// escapeMarkdown escapes a message so that it's rendered literally in Markdown.
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,97 @@
+package eris_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+)
+
+func TestToMarkdown(t *testing.T) {
+	tests := map[string]struct {
+		input   error
+		options eris.FormatOptions
+		output  string
+	}{
+		"nil error": {
+			input:  nil,
+			output: "",
+		},
+		"basic wrapped error": {
+			input: eris.Wrap(eris.New("root error"), "additional *context*"),
+			output: "<details>\n<summary>additional *context*: root error</summary>\n" +
+				"\n**additional \\*context\\***\n" +
+				"\n**root error**\n" +
+				"\n</details>\n",
+		},
+		"inverted external error": {
+			input:   eris.Wrap(errors.New("<external> error"), "additional context"),
+			options: eris.FormatOptions{InvertOutput: true, WithExternal: true},
+			output: "<details>\n<summary>&lt;external&gt; error: additional context</summary>\n" +
+				"\n**&lt;external&gt; error**\n" +
+				"\n**additional context**\n" +
+				"\n</details>\n",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.ToMarkdown(tt.input, tt.options); got != tt.output {
+				t.Errorf("ToMarkdown() got\n'%v'\nwant\n'%v'", got, tt.output)
+			}
+		})
+	}
+}
+
+func TestToMarkdownWithTrace(t *testing.T) {
+	err := eris.Wrap(eris.New("root error"), "additional context")
+	got := eris.ToMarkdown(err, eris.FormatOptions{WithTrace: true})
+
+	// the wrap error has a single frame and the root error stack contains the wrap frame as well
+	if n := strings.Count(got, "```"); n != 4 {
+		t.Fatalf("ToMarkdown() expected 2 code blocks, got\n'%v'", got)
+	}
+	if !strings.Contains(got, "eris_test.TestToMarkdownWithTrace:") {
+		t.Errorf("ToMarkdown() expected frames of the test function, got\n'%v'", got)
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	tests := map[string]struct {
+		input   error
+		options eris.FormatOptions
+		output  string
+	}{
+		"nil error": {
+			input:  nil,
+			output: "",
+		},
+		"basic wrapped error": {
+			input: eris.Wrap(eris.New("root <error>"), "additional context"),
+			output: "<div class=\"eris-error\">\n" +
+				"<p class=\"eris-summary\">additional context: root &lt;error&gt;</p>\n" +
+				"<p class=\"eris-link\">additional context</p>\n" +
+				"<p class=\"eris-link\">root &lt;error&gt;</p>\n" +
+				"</div>\n",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.ToHTML(tt.input, tt.options); got != tt.output {
+				t.Errorf("ToHTML() got\n'%v'\nwant\n'%v'", got, tt.output)
+			}
+		})
+	}
+}
+
+func TestToHTMLWithTrace(t *testing.T) {
+	err := eris.Wrap(eris.New("root error"), "additional context")
+	got := eris.ToHTML(err, eris.FormatOptions{WithTrace: true})
+
+	if n := strings.Count(got, "<details class=\"eris-link\">"); n != 2 {
+		t.Fatalf("ToHTML() expected 2 expandable sections, got\n'%v'", got)
+	}
+	if !strings.Contains(got, "<summary>additional context</summary><pre>\neris_test.TestToHTMLWithTrace:") {
+		t.Errorf("ToHTML() expected the wrap frame in the first section, got\n'%v'", got)
+	}
+}
@@ -0,0 +1,401 @@
+package eris
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseString parses the output of ToCustomString back into an UnpackedError. The format has to match the format
+// that was used to create the string (e.g. NewDefaultStringFormat with the same options).
+//
+// Only the messages and stack frames that are part of the string can be recovered, so the public messages, retry
+// classifications, and fields of the errors are always empty, and an external error is restored as a plain error with
+// its message. A few cases are ambiguous and parsed as follows:
+//
+//   - Without trace, the innermost message is parsed as the root error message, so an external error can't be told
+//     apart from the root error.
+//   - With trace, the root error is the last error with the most frames (the root stack contains the frames of its
+//     wrap errors) unless an earlier error has the same frames (the root error reuses the stack of an external error).
+//     The errors before the root error are wrap errors, and the errors after it are external errors. Additional frames
+//     of a wrap error are parsed as wrap errors without a message (see WithStack).
+//   - With MergeTrace, each message next to a frame is parsed as a single wrap error, even if it was merged from
+//     several wrap errors with the same frame.
+func ParseString(s string, format StringFormat) (UnpackedError, error) {
+	var upErr UnpackedError
+	if s == "" {
+		return upErr, nil
+	}
+	if format.ErrorSep == "" {
+		return upErr, New("eris: can't parse a string format without an error separator")
+	}
+	if !format.Options.WithTrace {
+		parseMessages(&upErr, s, format)
+		return upErr, nil
+	}
+
+	sections, err := parseSections(s, format)
+	if err != nil {
+		return upErr, err
+	}
+	if len(sections) == 0 {
+		return upErr, nil
+	}
+
+	// find the root error, which is the last error with the most frames or the first error with the same frames (the
+	// root error reuses the stack of an external error)
+	root := 0
+	for i, section := range sections {
+		if len(section.frames) >= len(sections[root].frames) {
+			root = i
+		}
+	}
+	for i := root - 1; i >= 0; i-- {
+		if reflect.DeepEqual(sections[i].frames, sections[root].frames) {
+			root = i
+		}
+	}
+
+	upErr.ErrRoot.Msg = sections[root].msg
+	for _, f := range stackOrder(sections[root].frames, format.Options.InvertTrace) {
+		upErr.ErrRoot.Stack = append(upErr.ErrRoot.Stack, f.StackFrame)
+		if f.note != "" {
+			upErr.ErrChain = append(upErr.ErrChain, ErrLink{Msg: f.note, Frame: f.StackFrame})
+		}
+	}
+
+	// sections are ordered from the outermost wrap error down to the innermost external error, while the chains are in
+	// stack trace order. The first frame of a wrap error belongs to its message, and each additional frame belongs to a
+	// wrap error without a message. The additional frames are wrapped by the message (i.e. they come before it in the
+	// chain) unless the output is inverted.
+	for i := root - 1; i >= 0; i-- {
+		section := sections[i]
+		links := make([]ErrLink, len(section.frames))
+		for j, f := range section.frames {
+			at := len(section.frames) - 1 - j
+			if format.Options.InvertOutput {
+				at = j
+			}
+			links[at] = ErrLink{Frame: f.StackFrame}
+			if j == 0 {
+				links[at].Msg = section.msg
+			}
+		}
+		if len(section.frames) == 0 {
+			links = append(links, ErrLink{Msg: section.msg})
+		}
+		upErr.ErrChain = append(upErr.ErrChain, links...)
+	}
+
+	var extMsgs []string
+	for _, section := range sections[root+1:] {
+		eLink := ErrExternalLink{Msg: section.msg}
+		for _, f := range stackOrder(section.frames, format.Options.InvertTrace) {
+			eLink.Stack = append(eLink.Stack, f.StackFrame)
+		}
+		upErr.ErrExternalChain = append([]ErrExternalLink{eLink}, upErr.ErrExternalChain...)
+		if section.msg != "" {
+			extMsgs = append(extMsgs, section.msg)
+		}
+	}
+	if len(upErr.ErrExternalChain) > 0 {
+		upErr.ErrExternal = errors.New(strings.Join(extMsgs, ": "))
+	}
+	return upErr, nil
+}
+
+// ParseJSON parses a JSON document created with ToCustomJSON (e.g. a JSON log entry) back into an UnpackedError. The
+// format has to match the format that was used to create the document (e.g. NewDefaultJSONFormat with the same
+// options).
+//
+// The messages, public messages, retry classifications, fields, and stack frames that are part of the document are
+// recovered, and an external error is restored as a plain error with its message. Field values are decoded as JSON
+// values (e.g. numbers are float64), and context errors that aren't context.Canceled or context.DeadlineExceeded are
+// restored as plain errors as well. With MergeTrace, each message next to a frame of the root stack is parsed as a
+// single wrap error like in ParseString.
+func ParseJSON(data []byte, format JSONFormat) (UnpackedError, error) {
+	var upErr UnpackedError
+	var doc jsonDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return upErr, Wrap(err, "eris: invalid JSON document")
+	}
+	frameRE := frameRegexp(format.StackElemSep, format.Options.MergeTrace)
+	parseStack := func(strs []string) ([]parsedFrame, error) {
+		var frames []parsedFrame
+		for _, str := range strs {
+			f, ok := parseFrame(frameRE, str)
+			if !ok {
+				return nil, Errorf("eris: invalid stack frame %q", str)
+			}
+			frames = append(frames, f)
+		}
+		return stackOrder(frames, format.Options.InvertTrace), nil
+	}
+
+	switch doc.Context {
+	case "":
+	case context.Canceled.Error():
+		upErr.ErrContext = context.Canceled
+	case context.DeadlineExceeded.Error():
+		upErr.ErrContext = context.DeadlineExceeded
+	default:
+		upErr.ErrContext = errors.New(doc.Context)
+	}
+
+	if doc.Root != nil {
+		upErr.ErrRoot.Msg = doc.Root.Message
+		upErr.ErrRoot.Public = doc.Root.Public
+		upErr.ErrRoot.Fields = doc.Root.Fields
+		upErr.ErrRoot.Retryable, upErr.ErrRoot.Permanent, upErr.ErrRoot.RetryAfter = doc.Root.retry()
+		frames, err := parseStack(doc.Root.Stack)
+		if err != nil {
+			return upErr, err
+		}
+		for _, f := range frames {
+			upErr.ErrRoot.Stack = append(upErr.ErrRoot.Stack, f.StackFrame)
+			if f.note != "" {
+				upErr.ErrChain = append(upErr.ErrChain, ErrLink{Msg: f.note, Frame: f.StackFrame})
+			}
+		}
+	}
+
+	// the wrap errors are ordered from the outermost wrap error down to the innermost one unless the output is
+	// inverted, while the chain is in stack trace order
+	for i := range doc.Wrap {
+		w := doc.Wrap[len(doc.Wrap)-1-i]
+		if format.Options.InvertOutput {
+			w = doc.Wrap[i]
+		}
+		eLink := ErrLink{Msg: w.Message, Public: w.Public, Fields: w.Fields}
+		eLink.Retryable, eLink.Permanent, eLink.RetryAfter = w.retry()
+		if w.Stack != "" {
+			f, ok := parseFrame(frameRE, w.Stack)
+			if !ok {
+				return upErr, Errorf("eris: invalid stack frame %q", w.Stack)
+			}
+			eLink.Frame = f.StackFrame
+		}
+		upErr.ErrChain = append(upErr.ErrChain, eLink)
+	}
+
+	if doc.External != nil {
+		upErr.ErrExternal = errors.New(*doc.External)
+	}
+	for i := range doc.ExternalChain {
+		l := doc.ExternalChain[len(doc.ExternalChain)-1-i]
+		if format.Options.InvertOutput {
+			l = doc.ExternalChain[i]
+		}
+		eLink := ErrExternalLink{Msg: l.Message, Type: l.Type}
+		frames, err := parseStack(l.Stack)
+		if err != nil {
+			return upErr, err
+		}
+		for _, f := range frames {
+			eLink.Stack = append(eLink.Stack, f.StackFrame)
+		}
+		upErr.ErrExternalChain = append(upErr.ErrExternalChain, eLink)
+	}
+	return upErr, nil
+}
+
+// jsonDoc is a JSON document created with ToCustomJSON.
+type jsonDoc struct {
+	Context       string         `json:"context"`
+	External      *string        `json:"external"`
+	ExternalChain []jsonExternal `json:"external_chain"`
+	Root          *jsonRoot      `json:"root"`
+	Wrap          []jsonWrap     `json:"wrap"`
+}
+
+// jsonError contains the fields that root and wrap errors have in common.
+type jsonError struct {
+	Message    string                 `json:"message"`
+	Public     string                 `json:"public"`
+	Retryable  *bool                  `json:"retryable"`
+	RetryAfter string                 `json:"retry_after"`
+	Fields     map[string]interface{} `json:"fields"`
+}
+
+// retry returns the retry classification of an error (see formatRetryJSON).
+func (e *jsonError) retry() (retryable, permanent bool, retryAfter time.Duration) {
+	if e.Retryable != nil {
+		retryable, permanent = *e.Retryable, !*e.Retryable
+	}
+	retryAfter, _ = time.ParseDuration(e.RetryAfter)
+	return retryable, permanent, retryAfter
+}
+
+type jsonRoot struct {
+	jsonError
+	Stack []string `json:"stack"`
+}
+
+type jsonWrap struct {
+	jsonError
+	Stack string `json:"stack"`
+}
+
+type jsonExternal struct {
+	Message string   `json:"message"`
+	Type    string   `json:"type"`
+	Stack   []string `json:"stack"`
+}
+
+// parsedSection is a single error message and its frames parsed from a string with trace.
+type parsedSection struct {
+	msg    string
+	frames []parsedFrame
+}
+
+// parsedFrame is a stack frame parsed from a string with trace. The note is the message of a merged wrap error.
+type parsedFrame struct {
+	StackFrame
+	note string
+}
+
+// parseMessages parses a string without trace, which only contains the error messages.
+func parseMessages(upErr *UnpackedError, s string, format StringFormat) {
+	msgs := strings.Split(s, format.ErrorSep)
+	if format.MsgStackSep != "" {
+		for i, msg := range msgs {
+			msgs[i] = strings.TrimSuffix(msg, format.MsgStackSep)
+		}
+	}
+	if format.Options.InvertOutput {
+		// the root error is first, followed by the wrap errors in stack trace order
+		upErr.ErrRoot.Msg = msgs[0]
+		for _, msg := range msgs[1:] {
+			upErr.ErrChain = append(upErr.ErrChain, ErrLink{Msg: msg})
+		}
+		return
+	}
+	upErr.ErrRoot.Msg = msgs[len(msgs)-1]
+	for i := len(msgs) - 2; i >= 0; i-- {
+		upErr.ErrChain = append(upErr.ErrChain, ErrLink{Msg: msgs[i]})
+	}
+}
+
+// parseSections splits a string with trace into its error messages and frames. The sections are returned from the
+// outermost wrap error down to the innermost external error, and the frames of each section are returned in output
+// order.
+func parseSections(s string, format StringFormat) ([]parsedSection, error) {
+	frameRE := frameRegexp(format.StackElemSep, format.Options.MergeTrace)
+	parseFrame := func(str string) (parsedFrame, bool) {
+		if !strings.HasPrefix(str, format.PreStackSep) {
+			return parsedFrame{}, false
+		}
+		return parseFrame(frameRE, strings.TrimPrefix(str, format.PreStackSep))
+	}
+
+	var sections []parsedSection
+	addFrame := func(f parsedFrame) {
+		if len(sections) == 0 {
+			sections = append(sections, parsedSection{})
+		}
+		last := &sections[len(sections)-1]
+		last.frames = append(last.frames, f)
+	}
+	for _, part := range strings.Split(s, format.ErrorSep) {
+		if part == "" {
+			continue
+		}
+		if format.Options.SourceLines > 0 && strings.HasPrefix(part, format.PreStackSep+format.PreStackSep) {
+			// source lines below a frame (see FormatOptions.SourceLines)
+			continue
+		}
+		if f, ok := parseFrame(part); ok {
+			addFrame(f)
+			continue
+		}
+		if format.PreStackSep != "" && strings.HasPrefix(part, format.PreStackSep) {
+			return nil, Errorf("eris: invalid stack frame %q", part)
+		}
+
+		// messages are followed by the first frame unless the separators are the same
+		msg := part
+		var first *parsedFrame
+		if format.MsgStackSep != format.ErrorSep && format.MsgStackSep != "" {
+			if at := strings.LastIndex(part, format.MsgStackSep); at >= 0 {
+				msg = part[:at]
+				if rest := part[at+len(format.MsgStackSep):]; rest != "" {
+					f, ok := parseFrame(rest)
+					if !ok {
+						return nil, Errorf("eris: invalid stack frame %q", rest)
+					}
+					first = &f
+				}
+			}
+		}
+		sections = append(sections, parsedSection{msg: msg})
+		if first != nil {
+			addFrame(*first)
+		}
+	}
+
+	if format.Options.InvertOutput {
+		for i, j := 0, len(sections)-1; i < j; i, j = i+1, j-1 {
+			sections[i], sections[j] = sections[j], sections[i]
+		}
+	}
+	return sections, nil
+}
+
+// stackOrder returns frames in stack trace order (i.e. the innermost frame first). The frames are in output order,
+// which is the reverse order unless the trace is inverted.
+func stackOrder(frames []parsedFrame, invert bool) []parsedFrame {
+	if invert {
+		return frames
+	}
+	ordered := make([]parsedFrame, len(frames))
+	for i, f := range frames {
+		ordered[len(frames)-1-i] = f
+	}
+	return ordered
+}
+
+// frameRegexpKey identifies a cached regular expression returned by frameRegexp.
+type frameRegexpKey struct {
+	sep    string
+	merged bool
+}
+
+// frameRegexps caches the compiled regular expressions of frameRegexp, so they aren't compiled for every parsed error.
+var frameRegexps sync.Map // map[frameRegexpKey]*regexp.Regexp
+
+// frameRegexp returns a regular expression that matches a stack frame formatted with the given separator. With
+// MergeTrace, frames can be followed by the message of a wrap error in parentheses.
+func frameRegexp(sep string, merged bool) *regexp.Regexp {
+	key := frameRegexpKey{sep: sep, merged: merged}
+	if re, ok := frameRegexps.Load(key); ok {
+		return re.(*regexp.Regexp)
+	}
+	sep = regexp.QuoteMeta(sep)
+	expr := `^(.*?)` + sep + `(.*?)` + sep + `(\d+)`
+	if merged {
+		expr += `(?: \((.*)\))?$`
+	} else {
+		expr += `()$`
+	}
+	re, _ := frameRegexps.LoadOrStore(key, regexp.MustCompile(expr))
+	return re.(*regexp.Regexp)
+}
+
+// parseFrame parses a stack frame that's matched by a regular expression returned by frameRegexp.
+func parseFrame(frameRE *regexp.Regexp, s string) (parsedFrame, bool) {
+	m := frameRE.FindStringSubmatch(s)
+	if m == nil {
+		return parsedFrame{}, false
+	}
+	line, err := strconv.Atoi(m[3])
+	if err != nil {
+		return parsedFrame{}, false
+	}
+	return parsedFrame{StackFrame: StackFrame{Name: m[1], File: m[2], Line: line}, note: m[4]}, true
+}
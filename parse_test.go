@@ -0,0 +1,346 @@
+package eris_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// parsed returns the parts of an unpacked error that are part of its string output.
+func parsed(upErr eris.UnpackedError, options eris.FormatOptions) eris.UnpackedError {
+	res := eris.UnpackedError{ErrRoot: eris.ErrRoot{Msg: upErr.ErrRoot.Msg}}
+	if options.WithTrace {
+		res.ErrRoot.Stack = upErr.ErrRoot.Stack
+	}
+	for _, eLink := range upErr.ErrChain {
+		if !options.WithTrace {
+			if eLink.Msg != "" {
+				res.ErrChain = append(res.ErrChain, eris.ErrLink{Msg: eLink.Msg})
+			}
+			continue
+		}
+		if options.MergeTrace && eLink.Msg == "" {
+			continue
+		}
+		res.ErrChain = append(res.ErrChain, eris.ErrLink{Msg: eLink.Msg, Frame: eLink.Frame})
+	}
+	if upErr.ErrExternal != nil {
+		res.ErrExternal = upErr.ErrExternal
+		for _, eLink := range upErr.ErrExternalChain {
+			res.ErrExternalChain = append(res.ErrExternalChain, eris.ErrExternalLink{Msg: eLink.Msg, Stack: eLink.Stack})
+		}
+	}
+	return res
+}
+
+// newWithStackError returns an error with wrap errors without a message between two wrap errors.
+func newWithStackError() error {
+	err := eris.New("root error")
+	err = eris.Wrap(err, "additional context")
+	err = eris.WithStack(err)
+	err = eris.WithStack(err)
+	return eris.Wrap(err, "more context")
+}
+
+func TestParseString(t *testing.T) {
+	tests := map[string]struct {
+		cause   error
+		input   []string
+		options eris.FormatOptions
+	}{
+		"root error without trace": {
+			cause: eris.New("root error"),
+			input: []string{"additional context", "even more context"},
+		},
+		"inverted output without trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{InvertOutput: true},
+		},
+		"root error with trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true},
+		},
+		"global error with trace": {
+			cause:   globalErr,
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true},
+		},
+		"inverted output with trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertOutput: true},
+		},
+		"inverted trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertTrace: true},
+		},
+		"inverted output and trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertOutput: true, InvertTrace: true},
+		},
+		"merged trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true, MergeTrace: true},
+		},
+		"source lines": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true, SourceLines: 2},
+		},
+		"wrap error without message": {
+			cause:   eris.WithStack(eris.New("root error")),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true},
+		},
+		"wrap error without message in the chain": {
+			cause:   newWithStackError(),
+			input:   []string{"even more context"},
+			options: eris.FormatOptions{WithTrace: true},
+		},
+		"inverted output with wrap error without message": {
+			cause:   newWithStackError(),
+			input:   []string{"even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertOutput: true},
+		},
+		"inverted output and trace with wrap error without message": {
+			cause:   newWithStackError(),
+			input:   []string{"even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertOutput: true, InvertTrace: true},
+		},
+		"external error": {
+			cause:   errors.New("external error"),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true, WithExternal: true},
+		},
+		"external error with stack": {
+			cause:   newExternalStackError(),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true, WithExternal: true},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if tt.options.SourceLines > 0 {
+				eris.SetSourceLoader(fakeSource(make(map[string]int)))
+				defer eris.SetSourceLoader(nil)
+			}
+			err := setupTestCase(false, tt.cause, tt.input)
+			format := eris.NewDefaultStringFormat(tt.options)
+			str := eris.ToCustomString(err, format)
+
+			got, perr := eris.ParseString(str, format)
+			if perr != nil {
+				t.Fatalf("ParseString() failed: %v", perr)
+			}
+			want := parsed(eris.Unpack(err), tt.options)
+			if want.ErrExternal != nil {
+				if got.ErrExternal == nil || got.ErrExternal.Error() != want.ErrExternal.Error() {
+					t.Errorf("ParseString() got external error '%v' want '%v'", got.ErrExternal, want.ErrExternal)
+				}
+				got.ErrExternal, want.ErrExternal = nil, nil
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseString() of\n%v\ngot %+v\nwant %+v", str, got, want)
+			}
+			if again := eris.ToCustomString(err, format); again != str {
+				t.Errorf("ToCustomString() got %q want %q", again, str)
+			}
+		})
+	}
+}
+
+func TestParseStringCustomFormat(t *testing.T) {
+	err := setupTestCase(false, eris.New("root error"), []string{"additional context", "even more context"})
+	format := eris.StringFormat{
+		Options:      eris.FormatOptions{WithTrace: true},
+		MsgStackSep:  " | ",
+		PreStackSep:  "at ",
+		StackElemSep: " @ ",
+		ErrorSep:     "\n",
+	}
+	str := eris.ToCustomString(err, format)
+	got, perr := eris.ParseString(str, format)
+	if perr != nil {
+		t.Fatalf("ParseString() failed: %v", perr)
+	}
+	if want := parsed(eris.Unpack(err), format.Options); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseString() of\n%v\ngot %+v\nwant %+v", str, got, want)
+	}
+}
+
+func TestParseStringErrors(t *testing.T) {
+	tests := map[string]struct {
+		input  string
+		format eris.StringFormat
+	}{
+		"invalid frame": {
+			input:  "root error\n\tnot a frame",
+			format: eris.NewDefaultStringFormat(eris.FormatOptions{WithTrace: true}),
+		},
+		"missing error separator": {
+			input: "root error",
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if _, err := eris.ParseString(tt.input, tt.format); err == nil {
+				t.Errorf("ParseString() got no error for %q", tt.input)
+			}
+		})
+	}
+}
+
+// parsedJSON returns the parts of an unpacked error that are part of its JSON document.
+func parsedJSON(upErr eris.UnpackedError, options eris.FormatOptions) eris.UnpackedError {
+	res := parsed(upErr, options)
+	res.ErrExternalChain = nil
+	if !options.WithExternal {
+		res.ErrExternal = nil
+	} else if options.WithTrace {
+		res.ErrExternalChain = upErr.ErrExternalChain
+	}
+	return res
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := map[string]struct {
+		cause   error
+		input   []string
+		options eris.FormatOptions
+	}{
+		"root error without trace": {
+			cause: eris.New("root error"),
+			input: []string{"additional context", "even more context"},
+		},
+		"root error with trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true},
+		},
+		"inverted output and trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertOutput: true, InvertTrace: true},
+		},
+		"merged trace": {
+			cause:   eris.New("root error"),
+			input:   []string{"additional context", "even more context"},
+			options: eris.FormatOptions{WithTrace: true, MergeTrace: true},
+		},
+		"wrap error without message": {
+			cause:   newWithStackError(),
+			input:   []string{"even more context"},
+			options: eris.FormatOptions{WithTrace: true, InvertOutput: true},
+		},
+		"external error": {
+			cause:   errors.New("external error"),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithExternal: true},
+		},
+		"external error with stack": {
+			cause:   newExternalStackError(),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true, WithExternal: true},
+		},
+		"inverted external error with stack": {
+			cause:   newExternalStackError(),
+			input:   []string{"additional context"},
+			options: eris.FormatOptions{WithTrace: true, WithExternal: true, InvertOutput: true, InvertTrace: true},
+		},
+	}
+
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			err := setupTestCase(false, tt.cause, tt.input)
+			format := eris.NewDefaultJSONFormat(tt.options)
+			data, jerr := json.Marshal(eris.ToCustomJSON(err, format))
+			if jerr != nil {
+				t.Fatal(jerr)
+			}
+
+			got, perr := eris.ParseJSON(data, format)
+			if perr != nil {
+				t.Fatalf("ParseJSON() failed: %v", perr)
+			}
+			want := parsedJSON(eris.Unpack(err), tt.options)
+			if want.ErrExternal != nil {
+				if got.ErrExternal == nil || got.ErrExternal.Error() != want.ErrExternal.Error() {
+					t.Errorf("ParseJSON() got external error '%v' want '%v'", got.ErrExternal, want.ErrExternal)
+				}
+				got.ErrExternal, want.ErrExternal = nil, nil
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseJSON() of\n%s\ngot %+v\nwant %+v", data, got, want)
+			}
+		})
+	}
+}
+
+func TestParseJSONDetails(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := eris.WithPublicMessage(eris.MarkRetryableAfter(eris.New("root error"), time.Second), "try again later")
+	err = eris.MarkPermanent(eris.WrapCtx(ctx, err, "additional context"))
+
+	format := eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true})
+	data, jerr := json.Marshal(eris.ToCustomJSON(err, format))
+	if jerr != nil {
+		t.Fatal(jerr)
+	}
+	got, perr := eris.ParseJSON(data, format)
+	if perr != nil {
+		t.Fatalf("ParseJSON() failed: %v", perr)
+	}
+	want := eris.Unpack(err)
+	if got.ErrContext != context.Canceled {
+		t.Errorf("ParseJSON() got context error '%v' want '%v'", got.ErrContext, context.Canceled)
+	}
+	if got.ErrRoot.Public != want.ErrRoot.Public || got.ErrRoot.Retryable != want.ErrRoot.Retryable ||
+		got.ErrRoot.RetryAfter != want.ErrRoot.RetryAfter {
+		t.Errorf("ParseJSON() got root %+v want %+v", got.ErrRoot, want.ErrRoot)
+	}
+	if len(got.ErrChain) != 1 || !got.ErrChain[0].Permanent || got.ErrChain[0].Retryable {
+		t.Errorf("ParseJSON() got chain %+v want a permanent wrap error", got.ErrChain)
+	}
+	if eris.Fingerprint(got) != eris.Fingerprint(want) {
+		t.Errorf("Fingerprint() of the parsed error got %v want %v", eris.Fingerprint(got), eris.Fingerprint(want))
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	tests := map[string]struct {
+		input string
+	}{
+		"invalid JSON": {
+			input: `{"root":`,
+		},
+		"invalid root": {
+			input: `{"root":"root error"}`,
+		},
+		"invalid frame": {
+			input: `{"root":{"message":"root error","stack":["not a frame"]}}`,
+		},
+		"invalid wrap frame": {
+			input: `{"root":{"message":"root error"},"wrap":[{"message":"additional context","stack":"not a frame"}]}`,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			format := eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true})
+			if _, err := eris.ParseJSON([]byte(tt.input), format); err == nil {
+				t.Errorf("ParseJSON() got no error for %q", tt.input)
+			}
+		})
+	}
+}
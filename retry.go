@@ -0,0 +1,78 @@
+package eris
+
+import "time"
+
+// retryClass classifies whether an error can be retried.
+type retryClass int
+
+const (
+	retryUnknown   retryClass = iota // the error isn't classified
+	retryRetryable                   // the error can be retried
+	retryPermanent                   // the error must not be retried
+)
+
+// MarkRetryable returns a copy of an error that's classified as retryable (see IsRetryable).
+//
+// For root and wrap errors, the classification is attached to a copy of the error, so the original error is left
+// unchanged. For external types, this method creates a new root error without a message that wraps the external
+// error. If err is nil, MarkRetryable returns nil.
+func MarkRetryable(err error) error {
+	return withMeta(err, func(m *meta) {
+		m.retry = retryRetryable
+		m.retryAfter = 0
+	})
+}
+
+// MarkRetryableAfter returns a copy of an error that's classified as retryable after a minimum delay (see
+// IsRetryable and RetryAfter). It's otherwise the same as MarkRetryable.
+func MarkRetryableAfter(err error, d time.Duration) error {
+	return withMeta(err, func(m *meta) {
+		m.retry = retryRetryable
+		m.retryAfter = d
+	})
+}
+
+// MarkPermanent returns a copy of an error that's classified as permanent, i.e. not retryable (see IsRetryable).
+// It's otherwise the same as MarkRetryable.
+func MarkPermanent(err error) error {
+	return withMeta(err, func(m *meta) {
+		m.retry = retryPermanent
+		m.retryAfter = 0
+	})
+}
+
+// IsRetryable reports whether an operation that failed with err can be retried.
+//
+// The chain is searched from the top for the first classified error. Errors are classified by MarkRetryable and
+// MarkPermanent, and external errors that implement `Temporary() bool` or `Timeout() bool` (e.g. net.Error) are
+// classified as retryable if either method returns true. Unclassified errors aren't retryable.
+func IsRetryable(err error) bool {
+	for err != nil {
+		if m := metaOf(err); m != nil && m.retry != retryUnknown {
+			return m.retry == retryRetryable
+		}
+		if e, ok := err.(interface{ Temporary() bool }); ok && e.Temporary() {
+			return true
+		}
+		if e, ok := err.(interface{ Timeout() bool }); ok && e.Timeout() {
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+// RetryAfter returns the minimum delay before retrying an operation that failed with err, as set by
+// MarkRetryableAfter. It returns false if the error isn't retryable or doesn't have a delay.
+func RetryAfter(err error) (time.Duration, bool) {
+	if !IsRetryable(err) {
+		return 0, false
+	}
+	for err != nil {
+		if m := metaOf(err); m != nil && m.retryAfter > 0 {
+			return m.retryAfter, true
+		}
+		err = Unwrap(err)
+	}
+	return 0, false
+}
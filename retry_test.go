@@ -0,0 +1,158 @@
+package eris_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// netError mimics a net.Error.
+type netError struct {
+	temporary bool
+	timeout   bool
+}
+
+func (e netError) Error() string   { return "network error" }
+func (e netError) Temporary() bool { return e.temporary }
+func (e netError) Timeout() bool   { return e.timeout }
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		input      error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		"unclassified error": {
+			input:     eris.Wrap(eris.New("root error"), "additional context"),
+			retryable: false,
+		},
+		"retryable root error": {
+			input:     eris.Wrap(eris.MarkRetryable(eris.New("root error")), "additional context"),
+			retryable: true,
+		},
+		"retryable global root error": {
+			input:     eris.Wrap(eris.MarkRetryable(globalErr), "additional context"),
+			retryable: true,
+		},
+		"retryable wrap error with delay": {
+			input:      eris.Wrap(eris.MarkRetryableAfter(eris.Wrap(eris.New("root error"), "additional context"), time.Second), "even more context"),
+			retryable:  true,
+			retryAfter: time.Second,
+		},
+		"permanent error wrapping a retryable error": {
+			input:     eris.MarkPermanent(eris.Wrap(eris.MarkRetryableAfter(eris.New("root error"), time.Second), "additional context")),
+			retryable: false,
+		},
+		"retryable error wrapping a permanent error": {
+			input:     eris.MarkRetryable(eris.Wrap(eris.MarkPermanent(eris.New("root error")), "additional context")),
+			retryable: true,
+		},
+		"temporary external error": {
+			input:     eris.Wrap(netError{temporary: true}, "additional context"),
+			retryable: true,
+		},
+		"timeout external error": {
+			input:     eris.Wrap(netError{timeout: true}, "additional context"),
+			retryable: true,
+		},
+		"permanent external error": {
+			input:     eris.Wrap(netError{}, "additional context"),
+			retryable: false,
+		},
+		"external error marked as permanent": {
+			input:     eris.Wrap(eris.MarkPermanent(netError{timeout: true}), "additional context"),
+			retryable: false,
+		},
+		"retryable external error": {
+			input:      eris.MarkRetryableAfter(errors.New("external error"), time.Minute),
+			retryable:  true,
+			retryAfter: time.Minute,
+		},
+		"nil error": {
+			input:     nil,
+			retryable: false,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.IsRetryable(tt.input); got != tt.retryable {
+				t.Errorf("IsRetryable() got { %v } want { %v }", got, tt.retryable)
+			}
+			got, ok := eris.RetryAfter(tt.input)
+			if got != tt.retryAfter || ok != (tt.retryAfter > 0) {
+				t.Errorf("RetryAfter() got { %v %v } want { %v %v }", got, ok, tt.retryAfter, tt.retryAfter > 0)
+			}
+		})
+	}
+
+	// the original error must not be changed
+	err := eris.New("root error")
+	_ = eris.MarkRetryable(err)
+	if eris.IsRetryable(err) {
+		t.Errorf("IsRetryable() got { true } want { false }")
+	}
+}
+
+var errTimeout = eris.MarkRetryable(context.DeadlineExceeded)
+
+func TestIsMarkedError(t *testing.T) {
+	tests := map[string]struct {
+		input  error
+		target error
+		output bool
+	}{
+		"marked sentinel": {
+			input:  eris.Wrap(errTimeout, "additional context"),
+			target: errTimeout,
+			output: true,
+		},
+		"external error of marked sentinel": {
+			input:  eris.Wrap(errTimeout, "additional context"),
+			target: context.DeadlineExceeded,
+			output: true,
+		},
+		"other marked external error": {
+			input:  eris.Wrap(eris.MarkRetryable(context.Canceled), "additional context"),
+			target: errTimeout,
+			output: false,
+		},
+		"other permanent external error": {
+			input:  eris.MarkPermanent(errors.New("external error")),
+			target: errTimeout,
+			output: false,
+		},
+		"external error with stack": {
+			input:  eris.WithStack(errors.New("external error")),
+			target: errTimeout,
+			output: false,
+		},
+		"same external error marked again": {
+			input:  eris.MarkRetryable(context.DeadlineExceeded),
+			target: errTimeout,
+			output: false,
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			if got := eris.Is(tt.input, tt.target); got != tt.output {
+				t.Errorf("eris.Is() got { %v } want { %v }", got, tt.output)
+			}
+			if got := errors.Is(tt.input, tt.target); got != tt.output {
+				t.Errorf("errors.Is() got { %v } want { %v }", got, tt.output)
+			}
+		})
+	}
+}
+
+func TestFormatRetryJSON(t *testing.T) {
+	err := eris.MarkPermanent(eris.Wrap(eris.MarkRetryableAfter(eris.New("root error"), time.Second), "additional context"))
+	result, _ := json.Marshal(eris.ToJSON(err, false))
+	want := `{"root":{"message":"root error","retry_after":"1s","retryable":true},"wrap":[{"message":"additional context","retryable":false}]}`
+	if got := string(result); got != want {
+		t.Errorf("ToJSON() got %v want %v", got, want)
+	}
+}
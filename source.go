@@ -0,0 +1,108 @@
+package eris
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SourceLoader returns the content of a source file, e.g. to read files from an embedded file system or a source
+// archive instead of the local file system.
+type SourceLoader func(file string) ([]byte, error)
+
+var (
+	sourceMu     sync.Mutex
+	sourceLoader SourceLoader = os.ReadFile
+	sourceCache               = make(map[string][]string)
+	sourceGen    int // incremented when the loader is changed, so files loaded by the previous loader aren't cached
+)
+
+// SetSourceLoader sets the loader for the source files that are shown with FormatOptions.SourceLines. A nil loader
+// restores the default loader, which reads files from the local file system. Loaded files are cached in memory until
+// the loader is changed.
+func SetSourceLoader(loader SourceLoader) {
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+	if loader == nil {
+		loader = os.ReadFile
+	}
+	sourceLoader = loader
+	sourceCache = make(map[string][]string)
+	sourceGen++
+}
+
+// sourceFile returns the lines of a source file or nil if it can't be loaded. The file is loaded without holding the
+// lock, so loading files doesn't block formatting other errors, and loaders can call SetSourceLoader themselves.
+func sourceFile(file string) []string {
+	sourceMu.Lock()
+	lines, ok := sourceCache[file]
+	loader, gen := sourceLoader, sourceGen
+	sourceMu.Unlock()
+	if ok {
+		return lines
+	}
+
+	if src, err := loader(file); err == nil {
+		lines = strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+	}
+
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+	// files that can't be loaded are cached as well, so they're only tried once
+	if gen == sourceGen {
+		sourceCache[file] = lines
+	}
+	return lines
+}
+
+// source returns n lines of source code before and after the line of a frame, or nil if its file can't be loaded.
+// The line of the frame is marked with ">".
+func (f *StackFrame) source(n int) []string {
+	if n <= 0 || f.Line <= 0 {
+		return nil
+	}
+	lines := sourceFile(f.File)
+	if f.Line > len(lines) {
+		return nil
+	}
+	first, last := f.Line-n, f.Line+n
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(fmt.Sprint(last))
+	var snippet []string
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == f.Line {
+			marker = ">"
+		}
+		snippet = append(snippet, fmt.Sprintf("%v %*d | %v", marker, width, i, lines[i-1]))
+	}
+	return snippet
+}
+
+// String formatter for the source code of a frame. Each line is indented with the PreStackSep twice, so it's shown
+// below the frame.
+func (f *StackFrame) formatSourceStr(format StringFormat) string {
+	var str string
+	for _, line := range f.source(format.Options.SourceLines) {
+		str += format.ErrorSep + format.PreStackSep + format.PreStackSep + line
+	}
+	return str
+}
+
+// JSON formatter for the source code of a stack. The source lines are keyed by the formatted frames, and frames
+// without source are omitted.
+func (s Stack) formatSourceJSON(format JSONFormat) map[string][]string {
+	source := make(map[string][]string)
+	for _, f := range s {
+		if lines := f.source(format.Options.SourceLines); len(lines) > 0 {
+			source[f.format(format.StackElemSep)] = lines
+		}
+	}
+	return source
+}
@@ -0,0 +1,194 @@
+package eris_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// fakeSource returns a source loader that returns the same file for every file name and counts the loaded files.
+func fakeSource(loaded map[string]int) eris.SourceLoader {
+	var lines []string
+	for i := 1; i <= 200; i++ {
+		lines = append(lines, "line "+strings.Repeat("x", i%3))
+	}
+	src := []byte(strings.Join(lines, "\n"))
+	return func(file string) ([]byte, error) {
+		loaded[file]++
+		return src, nil
+	}
+}
+
+func TestSourceLines(t *testing.T) {
+	loaded := make(map[string]int)
+	eris.SetSourceLoader(fakeSource(loaded))
+	defer eris.SetSourceLoader(nil)
+
+	err := eris.Wrap(eris.New("root error"), "additional context")
+	upErr := eris.Unpack(err)
+	n := upErr.ErrChain[0].Frame.Line
+	width := len(fmt.Sprint(n + 1))
+
+	options := eris.FormatOptions{WithTrace: true, SourceLines: 1}
+	str := eris.ToCustomString(err, eris.NewDefaultStringFormat(options))
+	lines := strings.Split(str, "\n")
+	if len(lines) < 5 || lines[0] != "additional context" {
+		t.Fatalf("ToCustomString() got unexpected output:\n%v", str)
+	}
+	// the wrap frame is followed by its source with the frame's line marked
+	want := []string{sourceLine(" ", n-1, width), sourceLine(">", n, width), sourceLine(" ", n+1, width)}
+	if !reflect.DeepEqual(lines[2:5], []string{"\t\t" + want[0], "\t\t" + want[1], "\t\t" + want[2]}) {
+		t.Errorf("ToCustomString() got source\n%v\nwant\n%v", strings.Join(lines[2:5], "\n"), strings.Join(want, "\n"))
+	}
+	// every frame of the root stack has source lines
+	rootAt := 5
+	if lines[rootAt] != "root error" {
+		t.Fatalf("ToCustomString() got '%v' want 'root error'", lines[rootAt])
+	}
+	if got, want := len(lines)-rootAt-1, 4*len(upErr.ErrRoot.Stack); got != want {
+		t.Errorf("ToCustomString() got %v root lines, want %v", got, want)
+	}
+
+	jsonMap := eris.ToCustomJSON(err, eris.NewDefaultJSONFormat(options))
+	wrapMap := jsonMap["wrap"].([]map[string]interface{})[0]
+	if source, _ := wrapMap["source"].([]string); !reflect.DeepEqual(source, want) {
+		t.Errorf("ToCustomJSON() got wrap source %v", wrapMap["source"])
+	}
+	rootMap := jsonMap["root"].(map[string]interface{})
+	source, _ := rootMap["source"].(map[string][]string)
+	for _, f := range upErr.ErrRoot.Stack {
+		if key := fmt.Sprintf("%v:%v:%v", f.Name, f.File, f.Line); len(source[key]) != 3 {
+			t.Errorf("ToCustomJSON() got root source %v, want source for %v", rootMap["source"], key)
+		}
+	}
+
+	// files are only loaded once
+	for file, count := range loaded {
+		if count != 1 {
+			t.Errorf("file %v was loaded %v times", file, count)
+		}
+	}
+}
+
+func TestSourceLinesUnavailable(t *testing.T) {
+	tests := map[string]struct {
+		loader eris.SourceLoader
+	}{
+		"missing file": {
+			loader: func(file string) ([]byte, error) {
+				return nil, errors.New("file not found")
+			},
+		},
+		"line out of range": {
+			loader: func(file string) ([]byte, error) {
+				return []byte("package eris_test\n"), nil
+			},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			eris.SetSourceLoader(tt.loader)
+			defer eris.SetSourceLoader(nil)
+
+			err := eris.Wrap(eris.New("root error"), "additional context")
+			format := eris.NewDefaultStringFormat(eris.FormatOptions{WithTrace: true, SourceLines: 2})
+			if str := eris.ToCustomString(err, format); strings.Contains(str, " | ") {
+				t.Errorf("ToCustomString() got source lines for an unavailable file:\n%v", str)
+			}
+			jsonFormat := eris.NewDefaultJSONFormat(eris.FormatOptions{WithTrace: true, SourceLines: 2})
+			if root := eris.ToCustomJSON(err, jsonFormat)["root"].(map[string]interface{}); root["source"] != nil {
+				t.Errorf("ToCustomJSON() got source lines for an unavailable file: %v", root["source"])
+			}
+		})
+	}
+}
+
+func TestSourceLinesDisabled(t *testing.T) {
+	loaded := make(map[string]int)
+	eris.SetSourceLoader(fakeSource(loaded))
+	defer eris.SetSourceLoader(nil)
+
+	err := eris.Wrap(eris.New("root error"), "additional context")
+	_ = eris.ToString(err, true)
+	_ = eris.ToJSON(err, true)
+	if len(loaded) > 0 {
+		t.Errorf("source files were loaded without SourceLines: %v", loaded)
+	}
+}
+
+func TestSourceLoaderWithoutLock(t *testing.T) {
+	defer eris.SetSourceLoader(nil)
+	var mu sync.Mutex
+	fake := fakeSource(make(map[string]int))
+	load := func(file string) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return fake(file)
+	}
+	loading, release := make(chan struct{}), make(chan struct{})
+	blocked := false
+	eris.SetSourceLoader(func(file string) ([]byte, error) {
+		mu.Lock()
+		block := !blocked
+		blocked = true
+		mu.Unlock()
+		if block {
+			// only the first load is blocked until the end of the test
+			close(loading)
+			<-release
+		}
+		return load(file)
+	})
+
+	err := eris.Wrap(eris.New("root error"), "additional context")
+	format := eris.NewDefaultStringFormat(eris.FormatOptions{WithTrace: true, SourceLines: 1})
+	go func() {
+		_ = eris.ToCustomString(err, format)
+	}()
+	<-loading
+	// other errors are formatted while the first file is still loading
+	done := make(chan struct{})
+	go func() {
+		_ = eris.ToCustomString(err, format)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ToCustomString() was blocked by a slow source loader")
+	}
+	close(release)
+
+	// loaders can change the loader without a deadlock
+	eris.SetSourceLoader(func(file string) ([]byte, error) {
+		eris.SetSourceLoader(load)
+		return load(file)
+	})
+	result := make(chan string)
+	go func() {
+		result <- eris.ToCustomString(err, format)
+	}()
+	select {
+	case str := <-result:
+		if !strings.Contains(str, fakeLine(1)) {
+			t.Errorf("ToCustomString() got no source lines:\n%v", str)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ToCustomString() didn't return after changing the loader in a loader")
+	}
+}
+
+func fakeLine(n int) string {
+	return "line " + strings.Repeat("x", n%3)
+}
+
+// sourceLine returns a formatted source line of the fake source with a line number of the given width.
+func sourceLine(marker string, n, width int) string {
+	return fmt.Sprintf("%v %*d | %v", marker, width, n, fakeLine(n))
+}
@@ -2,26 +2,43 @@ package eris
 
 import (
 	"fmt"
+	"reflect"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Stack is an array of stack frames stored in a human readable format.
 type Stack []StackFrame
 
-// format returns an array of formatted stack frames.
-func (s Stack) format(sep string, invert bool) []string {
+// format returns an array of formatted stack frames. Frames with an entry in msgs are annotated with that message.
+func (s Stack) format(sep string, invert bool, msgs map[int]string) []string {
 	var str []string
-	for _, f := range s {
+	for i, f := range s {
+		fStr := f.format(sep)
+		if msg, ok := msgs[i]; ok {
+			fStr += " (" + msg + ")"
+		}
 		if invert {
-			str = append(str, f.format(sep))
+			str = append(str, fStr)
 		} else {
-			str = append([]string{f.format(sep)}, str...)
+			str = append([]string{fStr}, str...)
 		}
 	}
 	return str
 }
 
+// index returns the position of a frame in the stack or -1 if the stack doesn't contain it.
+func (s Stack) index(f StackFrame) int {
+	for i, sf := range s {
+		if sf == f {
+			return i
+		}
+	}
+	return -1
+}
+
 // StackFrame stores a frame's runtime information in a human readable format.
 type StackFrame struct {
 	Name string
@@ -34,6 +51,11 @@ func (f *StackFrame) format(sep string) string {
 	return fmt.Sprintf("%v%v%v%v%v", f.Name, sep, f.File, sep, f.Line)
 }
 
+// formatCausedBy returns a stack frame formatted like a Java stack trace element.
+func (f *StackFrame) formatCausedBy() string {
+	return fmt.Sprintf("%v(%v:%v)", f.Name, f.File, f.Line)
+}
+
 // caller returns a single stack frame. the argument skip is the number of stack frames
 // to ascend, with 0 identifying the caller of Caller.
 func caller(skip int) *frame {
@@ -79,6 +101,13 @@ func callers(skip int) *stack {
 // stack is an array of program counters.
 type stack []uintptr
 
+// clone returns a copy of the stack.
+func (s *stack) clone() *stack {
+	c := make(stack, len(*s))
+	copy(c, *s)
+	return &c
+}
+
 // insertPC inserts a wrap error program counter (pc) into the correct place of the root error stack trace.
 func (s *stack) insertPC(wrapPCs stack) {
 	if len(wrapPCs) == 0 {
@@ -122,6 +151,151 @@ func (s *stack) get() []StackFrame {
 	return stackFrames
 }
 
+// NewStack returns a human readable stack trace for a program counter slice (e.g. the result of runtime.Callers or
+// StackFrames). Frames of the runtime package are omitted.
+func NewStack(pcs []uintptr) Stack {
+	st := trimRuntime(pcs)
+	if len(st) == 0 {
+		return nil
+	}
+	return st.get()
+}
+
+// trimRuntime returns the program counters that don't belong to the runtime package (e.g. runtime.main and
+// runtime.goexit at the bottom of each stack).
+func trimRuntime(pcs []uintptr) stack {
+	var st stack
+	for _, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && strings.HasPrefix(fn.Name(), "runtime.") {
+			continue
+		}
+		st = append(st, pc)
+	}
+	return st
+}
+
+// StackFunc returns the stack of an external error as a program counter slice (e.g. the result of runtime.Callers)
+// and reports whether the error has a stack.
+type StackFunc func(err error) ([]uintptr, bool)
+
+var (
+	stackFuncsMu     sync.RWMutex
+	stackFuncs       = make(map[string]StackFunc)
+	sortedStackFuncs []StackFunc // registered functions in the order of their names, replaced on every registration
+)
+
+// RegisterStackFunc registers a function that returns the stack of external errors, e.g. for the errors of another
+// error handling library. Registering a function under an existing name replaces it, and a nil function removes it.
+//
+// Registered functions are tried in the order of their names before the built-in interfaces, which recognize the
+// `StackFrames() []uintptr` and `Callers() []uintptr` methods and a `StackTrace()` method that returns a slice of
+// program counters (e.g. pkg/errors).
+func RegisterStackFunc(name string, fn StackFunc) {
+	stackFuncsMu.Lock()
+	defer stackFuncsMu.Unlock()
+	if fn == nil {
+		delete(stackFuncs, name)
+	} else {
+		stackFuncs[name] = fn
+	}
+	names := make([]string, 0, len(stackFuncs))
+	for name := range stackFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	funcs := make([]StackFunc, len(names))
+	for i, name := range names {
+		funcs[i] = stackFuncs[name]
+	}
+	sortedStackFuncs = funcs
+}
+
+// registeredStackFuncs returns the registered stack functions in the order of their names. The slice is never changed
+// after it's registered, so the functions are called without holding the lock and can register stack functions or
+// format errors themselves.
+func registeredStackFuncs() []StackFunc {
+	stackFuncsMu.RLock()
+	defer stackFuncsMu.RUnlock()
+	return sortedStackFuncs
+}
+
+// stackTraceMethods caches the index of the `StackTrace()` method of each external error type that returns a slice of
+// program counters, or -1 if the type doesn't have one, so the method is only looked up once per type.
+var stackTraceMethods sync.Map // map[reflect.Type]int
+
+// stackTraceMethod returns the index of the `StackTrace()` method of an error type or -1 if it doesn't have one.
+func stackTraceMethod(t reflect.Type) int {
+	if i, ok := stackTraceMethods.Load(t); ok {
+		return i.(int)
+	}
+	index := -1
+	if m, ok := t.MethodByName("StackTrace"); ok {
+		// the receiver is the first argument of the method type
+		mt := m.Type
+		if mt.NumIn() == 1 && mt.NumOut() == 1 {
+			if out := mt.Out(0); out.Kind() == reflect.Slice && out.Elem().Kind() == reflect.Uintptr {
+				index = m.Index
+			}
+		}
+	}
+	stackTraceMethods.Store(t, index)
+	return index
+}
+
+// externalPCs returns the program counters of an external error's stack without the frames of the runtime package
+// or nil if the error doesn't expose a stack.
+func externalPCs(err error) stack {
+	for _, fn := range registeredStackFuncs() {
+		if pcs, ok := fn(err); ok {
+			return trimRuntime(pcs)
+		}
+	}
+
+	var pcs []uintptr
+	switch e := err.(type) {
+	case interface{ StackFrames() []uintptr }:
+		pcs = e.StackFrames()
+	case interface{ Callers() []uintptr }:
+		pcs = e.Callers()
+	default:
+		// pkg/errors defines `type StackTrace []Frame` and `type Frame uintptr`, so reflection is used to avoid the
+		// dependency. It's only used if none of the interfaces above matched.
+		i := stackTraceMethod(reflect.TypeOf(err))
+		if i < 0 {
+			return nil
+		}
+		trace := reflect.ValueOf(err).Method(i).Call(nil)[0]
+		for i := 0; i < trace.Len(); i++ {
+			pcs = append(pcs, uintptr(trace.Index(i).Uint()))
+		}
+	}
+	return trimRuntime(pcs)
+}
+
+// externalStack returns the stack of an external error if it exposes one (see RegisterStackFunc).
+func externalStack(err error) Stack {
+	pcs := externalPCs(err)
+	if len(pcs) == 0 {
+		return nil
+	}
+	return pcs.get()
+}
+
+// originStack returns a copy of the stack of the innermost error in an external error chain that exposes one or nil
+// if there isn't one.
+func originStack(err error) *stack {
+	var origin stack
+	for ; err != nil; err = Unwrap(err) {
+		if pcs := externalPCs(err); len(pcs) > 0 {
+			origin = pcs
+		}
+	}
+	if origin == nil {
+		return nil
+	}
+	return &origin
+}
+
 // isGlobal determines if the stack trace represents a global error
 func (s *stack) isGlobal() bool {
 	frames := s.get()
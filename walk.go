@@ -0,0 +1,87 @@
+package eris
+
+import "time"
+
+// LinkKind identifies the type of an error in a chain.
+type LinkKind int
+
+const (
+	// WrapLink is a wrap error (see Wrap).
+	WrapLink LinkKind = iota
+	// RootLink is a root error (see New).
+	RootLink
+	// ExternalLink is any error that wasn't created by this package.
+	ExternalLink
+)
+
+// String returns the name of a link kind.
+func (k LinkKind) String() string {
+	switch k {
+	case WrapLink:
+		return "wrap"
+	case RootLink:
+		return "root"
+	case ExternalLink:
+		return "external"
+	}
+	return "unknown"
+}
+
+// Link represents a single error in a chain along with its frames and metadata.
+type Link struct {
+	Kind       LinkKind
+	Err        error                  // The error itself.
+	Msg        string                 // Message of this error only (complete message for external errors).
+	Frames     []StackFrame           // A single frame for wrap errors, the original stack for root errors, and nil otherwise.
+	Depth      int                    // Number of multi-error branches that lead to this error (0 for the main chain).
+	Public     string                 // Public message (see WithPublicMessage).
+	Retryable  bool                   // Flag indicating that the error was marked as retryable.
+	Permanent  bool                   // Flag indicating that the error was marked as permanent.
+	RetryAfter time.Duration          // Minimum delay before retrying (see MarkRetryableAfter).
+	Fields     map[string]interface{} // Request-scoped fields (see WrapCtx).
+}
+
+// Walk calls fn for each error in err's chain, starting at the top of the chain. Errors that implement
+// `Unwrap() []error` (e.g. errors created with errors.Join) are followed by each of their branches in order. The walk
+// stops as soon as fn returns false.
+func Walk(err error, fn func(link Link) bool) {
+	walkTree(err, 0, func(err error, depth int) bool {
+		return fn(newLink(err, depth))
+	})
+}
+
+// Chain returns all links in err's chain in the same order as Walk.
+func Chain(err error) []Link {
+	var links []Link
+	Walk(err, func(link Link) bool {
+		links = append(links, link)
+		return true
+	})
+	return links
+}
+
+// newLink returns the link of a single error.
+func newLink(err error, depth int) Link {
+	link := Link{Err: err, Depth: depth}
+	switch e := err.(type) {
+	case *rootError:
+		link.Kind = RootLink
+		link.Msg = e.msg
+		link.Frames = e.stack.get()
+	case *wrapError:
+		link.Kind = WrapLink
+		link.Msg = e.msg
+		link.Frames = []StackFrame{e.frame.get()}
+	default:
+		link.Kind = ExternalLink
+		link.Msg = err.Error()
+	}
+	if m := metaOf(err); m != nil {
+		link.Public = m.public
+		link.Retryable = m.retry == retryRetryable
+		link.Permanent = m.retry == retryPermanent
+		link.RetryAfter = m.retryAfter
+		link.Fields = m.fields
+	}
+	return link
+}
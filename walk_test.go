@@ -0,0 +1,105 @@
+package eris_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rotisserie/eris"
+)
+
+func TestChain(t *testing.T) {
+	type link struct {
+		kind   eris.LinkKind
+		msg    string
+		depth  int
+		frames bool
+	}
+	tests := map[string]struct {
+		input  error
+		output []link
+	}{
+		"nil error": {
+			input:  nil,
+			output: nil,
+		},
+		"wrapped root error": {
+			input: eris.Wrap(eris.Wrap(eris.New("root error"), "additional context"), "even more context"),
+			output: []link{
+				{kind: eris.WrapLink, msg: "even more context", frames: true},
+				{kind: eris.WrapLink, msg: "additional context", frames: true},
+				{kind: eris.RootLink, msg: "root error", frames: true},
+			},
+		},
+		"wrapped external error": {
+			input: eris.Wrap(eris.Wrap(fmt.Errorf("external context: %w", errors.New("external error")), "additional context"), "even more context"),
+			output: []link{
+				{kind: eris.WrapLink, msg: "even more context", frames: true},
+				{kind: eris.RootLink, msg: "additional context", frames: true},
+				{kind: eris.ExternalLink, msg: "external context: external error"},
+				{kind: eris.ExternalLink, msg: "external error"},
+			},
+		},
+		"wrapped multi error": {
+			input: eris.Wrap(joinError{
+				eris.Wrap(eris.New("first error"), "first context"),
+				errors.New("second error"),
+			}, "additional context"),
+			output: []link{
+				{kind: eris.RootLink, msg: "additional context", frames: true},
+				{kind: eris.ExternalLink, msg: "[first context: first error second error]"},
+				{kind: eris.WrapLink, msg: "first context", depth: 1, frames: true},
+				{kind: eris.RootLink, msg: "first error", depth: 1, frames: true},
+				{kind: eris.ExternalLink, msg: "second error", depth: 1},
+			},
+		},
+	}
+	for desc, tt := range tests {
+		t.Run(desc, func(t *testing.T) {
+			links := eris.Chain(tt.input)
+			if len(links) != len(tt.output) {
+				t.Fatalf("Chain() got %v links want %v: %+v", len(links), len(tt.output), links)
+			}
+			for i, want := range tt.output {
+				got := links[i]
+				if got.Kind != want.kind || got.Msg != want.msg || got.Depth != want.depth || (len(got.Frames) > 0) != want.frames {
+					t.Errorf("Chain() link %v got { %v %v %v %v } want { %v %v %v %v }", i,
+						got.Kind, got.Msg, got.Depth, len(got.Frames), want.kind, want.msg, want.depth, want.frames)
+				}
+				if got.Kind == eris.WrapLink && len(got.Frames) != 1 {
+					t.Errorf("Chain() expected a single frame for wrap errors, got %v", got.Frames)
+				}
+			}
+		})
+	}
+}
+
+func TestWalk(t *testing.T) {
+	err := eris.Wrap(eris.MarkRetryable(eris.WithPublicMessage(eris.New("root error"), "public error")), "additional context")
+
+	var kinds []string
+	var root eris.Link
+	eris.Walk(err, func(link eris.Link) bool {
+		kinds = append(kinds, link.Kind.String())
+		if link.Kind == eris.RootLink {
+			root = link
+		}
+		return true
+	})
+	if fmt.Sprint(kinds) != "[wrap root]" {
+		t.Errorf("Walk() got kinds %v want [wrap root]", kinds)
+	}
+	if root.Public != "public error" || !root.Retryable || root.Permanent {
+		t.Errorf("Walk() got root metadata { %v %v %v } want { public error true false }", root.Public, root.Retryable, root.Permanent)
+	}
+
+	// stop after the first link
+	var n int
+	eris.Walk(err, func(link eris.Link) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Walk() visited %v links after stopping want 1", n)
+	}
+}